@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"go.uber.org/zap"
+	"io/fs"
+	"os"
+	"os/signal"
+	"shop/configs/env"
+	"shop/configs/pg_conf"
+	"shop/db"
+	"shop/internal/container"
+	"shop/pkg/log"
+	"shop/pkg/migrate"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const commandsUsage = `Usage: shop [command]
+
+Without a command the HTTP server is started.
+
+Commands:
+  migrate up                   apply all pending migrations
+  migrate down [-steps N]      revert the last N applied migrations (default 1)
+  migrate status               list migrations and when they were applied
+  migrate baseline VERSION     mark migrations up to VERSION as applied without running them
+  search-backfill [-batch N]   recompute search vectors of all nodes
+`
+
+// runCommand выполняет служебную команду вместо запуска сервера и возвращает код выхода.
+func runCommand(args []string) int {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(args[1:])
+	case "search-backfill":
+		return runSearchBackfill(args[1:])
+	case "help", "-h", "--help":
+		fmt.Print(commandsUsage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", args[0], commandsUsage)
+		return 2
+	}
+}
+
+// initCommand готовит окружение для служебных команд: переменные окружения, логгер и Postgres.
+func initCommand() *sql.DB {
+	env.LoadEnv()
+	log.InitLogger()
+	pg_conf.InitPostgresSingleton()
+	return pg_conf.GetDB()
+}
+
+// runSearchBackfill пересчитывает поисковые векторы нод, например после миграции 0002_weighted_search.
+func runSearchBackfill(args []string) int {
+	flags := flag.NewFlagSet("search-backfill", flag.ContinueOnError)
+	batch := flags.Int("batch", 500, "nodes per batch")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	deps := container.New(initCommand())
+
+	// Прерывание по Ctrl+C отменяет текущий пакет; уже пересчитанные пакеты сохраняются
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	total, err := deps.Services.Node.BackfillSearchVectors(ctx, *batch)
+	if err != nil {
+		log.Error("Search backfill failed", zap.Int("processed", total), zap.Error(err))
+		return 1
+	}
+
+	log.Info("Search backfill finished", zap.Int("processed", total))
+	return 0
+}
+
+// newMigrator создаёт мигратор по встроенным в бинарник миграциям db/migrations.
+func newMigrator(conn *sql.DB) (*migrate.Migrator, error) {
+	migrations, err := fs.Sub(db.Migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(conn, migrations)
+}
+
+// runMigrate выполняет подкоманду migrate: up, down, status или baseline.
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, commandsUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	steps := flags.Int("steps", 1, "number of migrations to revert")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	migrator, err := newMigrator(initCommand())
+	if err != nil {
+		log.Error("Failed to load migrations", zap.Error(err))
+		return 1
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			log.Info("Migration applied", zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+		}
+		if err != nil {
+			log.Error("Migration failed", zap.Error(err))
+			return 1
+		}
+		log.Info("Database is up to date", zap.Int("applied", len(applied)))
+	case "down":
+		reverted, err := migrator.Down(ctx, *steps)
+		for _, migration := range reverted {
+			log.Info("Migration reverted", zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+		}
+		if err != nil {
+			log.Error("Migration rollback failed", zap.Error(err))
+			return 1
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Error("Failed to read migration status", zap.Error(err))
+			return 1
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d  %-32s %s\n", status.Version, status.Name, appliedAt)
+		}
+	case "baseline":
+		if flags.NArg() != 1 {
+			fmt.Fprint(os.Stderr, commandsUsage)
+			return 2
+		}
+		version, err := strconv.ParseInt(flags.Arg(0), 10, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid version %q\n", flags.Arg(0))
+			return 2
+		}
+		if err := migrator.Baseline(ctx, version); err != nil {
+			log.Error("Failed to baseline migrations", zap.Error(err))
+			return 1
+		}
+		log.Info("Migrations baselined", zap.Int64("version", version))
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown migrate command %q\n\n%s", args[0], commandsUsage)
+		return 2
+	}
+
+	return 0
+}
+
+// autoMigrate применяет недостающие миграции при старте сервера, если AUTO_MIGRATE=true.
+func autoMigrate(conn *sql.DB) error {
+	if enabled, _ := strconv.ParseBool(env.GetEnv("AUTO_MIGRATE", "false")); !enabled {
+		return nil
+	}
+
+	migrator, err := newMigrator(conn)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(context.Background())
+	for _, migration := range applied {
+		log.Info("Migration applied", zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+	}
+	return err
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 	"os"
 	"os/signal"
@@ -11,37 +13,55 @@ import (
 	"shop/configs/pg_conf"
 	"shop/internal/api/middlewares"
 	"shop/internal/api/routes"
+	"shop/internal/container"
 	"shop/pkg/log"
 	"syscall"
 	"time"
 )
 
 func main() {
-	initApp()
-	app := fiber.New()
+	// Служебные команды (например, search-backfill) выполняются без запуска сервера
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
+	deps := initApp()
+	app := fiber.New(fiber.Config{
+		// Ошибки хендлеров и middleware отправляются клиенту в формате http_error.HTTPError
+		ErrorHandler: middlewares.ErrorHandler,
+	})
 
 	// Middleware: CORS
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*", // Allow all origins, consider limiting this for production
-		AllowMethods: "GET,POST,PUT,DELETE",
+		AllowMethods: "GET,POST,PUT,PATCH,DELETE",
 	}))
 
-	// Middleware: Global error handling
-	//app.Use(middlewares.ErrorHandlerMiddleware(container.Logger))
-	////Middleware: Request logging
+	// Middleware: Panic recovery. Стоит первым после CORS: fasthttp не перехватывает панику сам,
+	// поэтому паника в любом следующем middleware, включая метрики и логгер, иначе роняет процесс.
+	// X-Request-ID для лога паники логгер проставляет до вызова следующих обработчиков
+	app.Use(middlewares.RecoverMiddleware())
+	// Middleware: Prometheus metrics (перед логгером, чтобы видеть итоговый статус ответа)
+	app.Use(middlewares.MetricsMiddleware())
+	// Middleware: Request logging
 	app.Use(middlewares.RequestLoggerMiddleware())
 	app.Use(middlewares.LimitQueryParamsMiddleware)
+	app.Use(middlewares.RequestDeadlineMiddleware())
 
 	groupApi := app.Group("/api")
 
 	// Register routes
-	routes.RegisterSizeRoutes(groupApi)
-	routes.RegisterCharacteristicRoutes(groupApi)
-	routes.RegisterCharDefaultValueRoutes(groupApi)
-	routes.RegisterNodeTypeRoutes(groupApi)
-	routes.RegisterNodeRoutes(groupApi)
-	routes.RegisterCardRoutes(groupApi)
-	routes.RegisterOrderRoutes(groupApi)
+	routes.RegisterAuthRoutes(groupApi, deps)
+	routes.RegisterUserRoutes(groupApi, deps)
+	routes.RegisterSizeRoutes(groupApi, deps)
+	routes.RegisterStockRoutes(groupApi, deps)
+	routes.RegisterCharacteristicRoutes(groupApi, deps)
+	routes.RegisterCharDefaultValueRoutes(groupApi, deps)
+	routes.RegisterNodeTypeRoutes(groupApi, deps)
+	routes.RegisterNodeRoutes(groupApi, deps)
+	routes.RegisterCardRoutes(groupApi, deps)
+	routes.RegisterOrderRoutes(groupApi, deps)
+	routes.RegisterAdminRoutes(groupApi, deps)
 
 	// Start the server
 	port := env.GetEnv("SERV_PORT", "3000")
@@ -54,12 +74,25 @@ func main() {
 		}
 	}()
 
+	// Prometheus scrape endpoint слушает отдельный порт: метрики без аутентификации,
+	// поэтому наружу публикуется только SERV_PORT, а METRICS_PORT остаётся во внутренней сети
+	metricsApp := fiber.New(fiber.Config{DisableStartupMessage: true})
+	metricsApp.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
+
+	metricsPort := env.GetEnv("METRICS_PORT", "9090")
+	log.Info("Starting metrics server", zap.String("port", metricsPort))
+	go func() {
+		if err := metricsApp.Listen(":" + metricsPort); err != nil {
+			log.Fatal("Failed to start metrics server", zap.Error(err))
+		}
+	}()
+
 	// Call graceful shutdown handler
-	handleGracefulShutdown(app)
+	handleGracefulShutdown(app, metricsApp)
 }
 
-// handleGracefulShutdown handles signal-based graceful shutdown
-func handleGracefulShutdown(app *fiber.App) {
+// handleGracefulShutdown handles signal-based graceful shutdown of all apps
+func handleGracefulShutdown(apps ...*fiber.App) {
 	// Create a channel to receive OS signals
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
@@ -73,16 +106,39 @@ func handleGracefulShutdown(app *fiber.App) {
 	defer cancel()
 
 	log.Info("Shutting down server...")
-	if err := app.ShutdownWithContext(ctx); err != nil {
-		log.Error("Failed to gracefully shutdown server", zap.Error(err))
-	} else {
-		log.Info("Server shut down gracefully")
+	for _, app := range apps {
+		if err := app.ShutdownWithContext(ctx); err != nil {
+			log.Error("Failed to gracefully shutdown server", zap.Error(err))
+			return
+		}
 	}
+	log.Info("Server shut down gracefully")
 }
 
-func initApp() {
+// initApp загружает окружение, подключается к Postgres и собирает контейнер зависимостей приложения.
+func initApp() *container.Container {
 	// Load environment variables
 	env.LoadEnv()
 	log.InitLogger()
+
+	if env.GetEnv("JWT_KEY", "") == "" {
+		log.Fatal("JWT_KEY is not set in environment variables")
+	}
+
 	pg_conf.InitPostgresSingleton()
+	db := pg_conf.GetDB()
+
+	// При AUTO_MIGRATE=true схема доводится до последней версии до обращения к таблицам
+	if err := autoMigrate(db); err != nil {
+		log.Fatal("Failed to migrate database", zap.Error(err))
+	}
+
+	deps := container.New(db)
+
+	// Создаём первого администратора из SUPER_ADMIN_LOGIN / SUPER_ADMIN_PASSWORD
+	if err := deps.Services.User.BootstrapSuperAdmin(context.Background()); err != nil {
+		log.Fatal("Failed to bootstrap super admin", zap.Error(err))
+	}
+
+	return deps
 }
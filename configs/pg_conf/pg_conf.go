@@ -6,6 +6,7 @@ import (
 	"go.uber.org/zap"
 	"os"
 	"shop/pkg/log"
+	"shop/pkg/metrics"
 	"sync"
 	"time"
 
@@ -15,14 +16,13 @@ import (
 var (
 	postgresDB    *sql.DB
 	once          sync.Once
-	postgresURI   string
 	checkInterval = 15 * time.Second
 )
 
 // InitPostgresSingleton инициализирует подключение к PostgreSQL в виде синглтона и запускает мониторинг соединения.
 func InitPostgresSingleton() {
 	once.Do(func() {
-		postgresURI = os.Getenv("POSTGRES_URI")
+		postgresURI := os.Getenv("POSTGRES_URI")
 
 		if postgresURI == "" {
 			log.Fatal("POSTGRES_URI is not set in environment variables")
@@ -34,6 +34,7 @@ func InitPostgresSingleton() {
 
 		log.Info("Connected to Postgres successfully!")
 		postgresDB = db
+		metrics.RegisterDB(db)
 
 		//// Запускаем горутину для периодической проверки соединения и реконнекта
 		go monitorConnection()
@@ -62,39 +63,26 @@ func connectPostgres(uri string) (*sql.DB, error) {
 	return db, nil
 }
 
-// monitorConnection каждые 15 секунд проверяет соединение и при необходимости пытается переподключиться.
+// monitorConnection каждые 15 секунд проверяет соединение. Пул *sql.DB сам заменяет разорванные
+// соединения новыми, поэтому экземпляр не пересоздаётся: репозитории держат ссылку на него.
 func monitorConnection() {
 	ticker := time.NewTicker(checkInterval)
 	defer ticker.Stop()
 
+	healthy := true
 	for range ticker.C {
 		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 		err := postgresDB.PingContext(ctx)
 		cancel()
 
-		if err != nil {
-			log.Error("Postgres connection lost, trying to reconnect...", zap.Error(err))
-			reconnect()
-		}
-	}
-}
-
-// reconnect пытается переподключиться к БД до успешного результата.
-func reconnect() {
-	for {
-		db, err := connectPostgres(postgresURI)
-		if err == nil {
-			oldDB := postgresDB
-			postgresDB = db
-			if oldDB != nil {
-				oldDB.Close()
-			}
+		if err != nil && healthy {
+			metrics.DBConnectionLost.Inc()
+			log.Error("Postgres connection lost, waiting for it to come back...", zap.Error(err))
+		} else if err == nil && !healthy {
+			metrics.DBReconnects.Inc()
 			log.Info("Reconnected to Postgres successfully!")
-			return
-		} else {
-			log.Error("Failed to reconnect to Postgres, will retry in 15 seconds", zap.Error(err))
-			time.Sleep(checkInterval)
 		}
+		healthy = err == nil
 	}
 }
 
@@ -0,0 +1,9 @@
+// Package db содержит миграции схемы базы данных, встроенные в бинарник.
+package db
+
+import "embed"
+
+// Migrations — SQL-миграции вида NNNN_name.up.sql / NNNN_name.down.sql (см. pkg/migrate).
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
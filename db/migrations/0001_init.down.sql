@@ -0,0 +1,9 @@
+DROP SCHEMA IF EXISTS shop CASCADE;
+DROP TABLE IF EXISTS size;
+
+DROP FUNCTION IF EXISTS set_created_at();
+DROP FUNCTION IF EXISTS set_updated_at();
+DROP FUNCTION IF EXISTS fn_update_nodes_search_vector();
+DROP FUNCTION IF EXISTS fn_characteristics_update_nodes();
+
+-- Расширение pg_trgm не удаляется: им могут пользоваться и другие схемы базы.
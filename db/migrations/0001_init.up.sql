@@ -1,3 +1,6 @@
+-- Базовая схема: соответствует прежнему скрипту db/database_setup3.sql.
+-- Расхождения с кодом (price_run, таблица size в схеме public) исправляет 0003_fix_price_rub_and_size.
+
 -- =========================================
 -- 1. Создать схему shop
 -- =========================================
@@ -49,6 +52,10 @@ CREATE TABLE shop.nodes
 CREATE INDEX idx_nodes_created_at
     ON shop.nodes (created_at);
 
+-- Индекс для курсорной пагинации карточек по (created_at, id)
+CREATE INDEX idx_nodes_created_at_id
+    ON shop.nodes (created_at DESC, id DESC);
+
 
 
 CREATE TABLE shop.char_default_value
@@ -233,34 +240,138 @@ EXECUTE PROCEDURE fn_characteristics_update_nodes();
 
 
 
+-- ======================================
+-- ======================================
+-- ORDERS
+-- ======================================
+-- ======================================
+
+CREATE TABLE shop.orders
+(
+    id         UUID PRIMARY KEY,
+    status     TEXT      NOT NULL DEFAULT 'new',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_orders_created_at
+    ON shop.orders (created_at);
+
+-- Позиции заказа. Цены и скидка копируются из shop.nodes в момент оформления,
+-- чтобы последующее изменение карточки не меняло уже оформленный заказ.
+CREATE TABLE shop.order_items
+(
+    id        SERIAL PRIMARY KEY,
+    order_id  UUID NOT NULL
+        REFERENCES shop.orders (id)
+            ON DELETE CASCADE,
+    node_id   INT  NOT NULL
+        REFERENCES shop.nodes (id),
+    size      TEXT,
+    amount    INT  NOT NULL CHECK (amount > 0),
+    price_byn INT,
+    price_rub INT,
+    discount  INT
+);
+
+CREATE INDEX idx_order_items_order_id
+    ON shop.order_items (order_id);
+
+CREATE INDEX idx_orders_status
+    ON shop.orders (status);
+
+-- История смены статусов заказа: кто, когда и из какого статуса в какой перевёл заказ.
+CREATE TABLE shop.order_status_history
+(
+    id          SERIAL PRIMARY KEY,
+    order_id    UUID      NOT NULL
+        REFERENCES shop.orders (id)
+            ON DELETE CASCADE,
+    from_status TEXT,
+    to_status   TEXT      NOT NULL,
+    changed_by  TEXT,
+    comment     TEXT,
+    changed_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_order_status_history_order_id
+    ON shop.order_status_history (order_id);
+
+
+-- ======================================
+-- ======================================
+-- STOCK
+-- ======================================
+-- ======================================
+
+-- Остатки товара по размерам. reserved — сколько единиц уже зарезервировано
+-- оформленными, но ещё не отгруженными заказами.
+CREATE TABLE shop.stock
+(
+    id       SERIAL PRIMARY KEY,
+    node_id  INT NOT NULL
+        REFERENCES shop.nodes (id)
+            ON DELETE CASCADE,
+    size_id  INT NOT NULL
+        REFERENCES size (id)
+            ON DELETE CASCADE,
+    quantity INT NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    reserved INT NOT NULL DEFAULT 0 CHECK (reserved >= 0),
+    UNIQUE (node_id, size_id),
+    CHECK (reserved <= quantity)
+);
+
+-- Размер, под который была зарезервирована позиция заказа (NULL — товар без учёта остатков).
+ALTER TABLE shop.order_items
+    ADD COLUMN size_id INT REFERENCES size (id);
+
+
+-- ======================================
+-- ======================================
+-- USERS
+-- ======================================
+-- ======================================
+
+CREATE TABLE shop.users
+(
+    id            SERIAL PRIMARY KEY,
+    email         TEXT      NOT NULL UNIQUE,
+    phone         TEXT,
+    password_hash TEXT      NOT NULL,
+    role          TEXT      NOT NULL DEFAULT 'customer'
+        CHECK (role IN ('admin', 'manager', 'customer')),
+    created_at    TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Владелец заказа (NULL — заказ оформлен без авторизации).
+ALTER TABLE shop.orders
+    ADD COLUMN user_id INT REFERENCES shop.users (id) ON DELETE SET NULL;
 
-SELECT *
-FROM shop.nodes
-WHERE search_vector @@ plainto_tsquery('russian', 'майка летняя XXL');
+CREATE INDEX idx_orders_user_id ON shop.orders (user_id);
 
+-- Refresh-токены. Хранится только bcrypt-хеш секрета; family_id объединяет цепочку ротаций одного входа.
+CREATE TABLE shop.refresh_tokens
+(
+    id         UUID PRIMARY KEY,
+    family_id  UUID      NOT NULL,
+    user_id    INT       NOT NULL REFERENCES shop.users (id) ON DELETE CASCADE,
+    token_hash TEXT      NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    rotated_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
 
--- обновить search_vector в nodes где есть null
-UPDATE shop.nodes n
-SET search_vector = to_tsvector(
-        'russian', -- Или 'simple', 'english' и т.п., в зависимости от языка
-        COALESCE(n.title, '') || ' '
-            || COALESCE(n.description, '') || ' '
-            || COALESCE(nt.type, '') || ' '
-            || COALESCE(
-                (SELECT string_agg(
-                                c.title || ' ' || cv.value,
-                                ' '
-                        )
-                 FROM shop.characteristic_values cv
-                          JOIN shop.characteristics c ON c.id = cv.characteristic_id
-                 WHERE cv.node_id = n.id
-                   AND c.is_visible = true),
-                ''
-               )
-                    )
-FROM shop.node_types nt
-WHERE n.search_vector IS NULL
-  AND nt.id = n.node_type_id;
+CREATE INDEX idx_refresh_tokens_family_id ON shop.refresh_tokens (family_id);
+CREATE INDEX idx_refresh_tokens_user_id ON shop.refresh_tokens (user_id);
 
+-- ================================================================
+-- ПОДСКАЗКИ ПОИСКА (pg_trgm)
+-- ================================================================
 
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
 
+-- Индексы для операторов %, <% и ILIKE в /cards/suggest
+CREATE INDEX idx_nodes_title_trgm ON shop.nodes USING GIN (title gin_trgm_ops);
+CREATE INDEX idx_node_types_type_trgm ON shop.node_types USING GIN (type gin_trgm_ops);
+CREATE INDEX idx_char_default_value_value_trgm ON shop.char_default_value USING GIN (value gin_trgm_ops);
@@ -0,0 +1,50 @@
+DROP TRIGGER IF EXISTS tr_node_types_update_nodes ON shop.node_types;
+DROP FUNCTION IF EXISTS fn_node_types_update_nodes();
+
+CREATE OR REPLACE FUNCTION set_updated_at()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    -- Check if the update affects any column other than `removed_at`
+    IF NOT TG_OP = 'UPDATE' OR ROW (NEW.*) IS DISTINCT FROM ROW (OLD.*) THEN
+        NEW.updated_at := NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION fn_update_nodes_search_vector()
+    RETURNS TRIGGER AS
+$$
+DECLARE
+    v_type        text;
+    v_char_values text;
+BEGIN
+    SELECT nt.type
+    INTO v_type
+    FROM shop.node_types nt
+    WHERE nt.id = NEW.node_type_id;
+
+    SELECT string_agg(c.title || ' ' || cv.value, ' ')
+    INTO v_char_values
+    FROM shop.characteristic_values cv
+             JOIN shop.characteristics c ON c.id = cv.characteristic_id
+    WHERE cv.node_id = NEW.id
+      AND c.is_visible = true;
+
+    NEW.search_vector := to_tsvector(
+            'russian',
+            COALESCE(NEW.title, '') || ' '
+                || COALESCE(NEW.description, '') || ' '
+                || COALESCE(v_type, '') || ' '
+                || COALESCE(v_char_values, '')
+                         );
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE shop.node_types
+    DROP COLUMN IF EXISTS search_config;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS public.belarusian;
@@ -0,0 +1,77 @@
+-- Взвешенный поисковый вектор и конфигурация полнотекстового поиска на уровне типа ноды.
+-- Применяется поверх базовой схемы 0001_init.
+-- После применения нужно пересчитать search_vector существующих нод: shop search-backfill.
+
+-- В PostgreSQL нет стеммера для белорусского языка, поэтому belarusian — копия simple:
+-- слова только приводятся к нижнему регистру. Конфигурация лежит в public, чтобы имя
+-- 'belarusian' разрешалось без схемы так же, как встроенные russian/english/simple.
+CREATE TEXT SEARCH CONFIGURATION public.belarusian (COPY = pg_catalog.simple);
+
+ALTER TABLE shop.node_types
+    ADD COLUMN search_config regconfig NOT NULL DEFAULT 'russian';
+
+-- Вес частей вектора: название (A), тип (B), характеристики (C), описание (D).
+CREATE OR REPLACE FUNCTION fn_update_nodes_search_vector()
+    RETURNS TRIGGER AS
+$$
+DECLARE
+    v_type        text;
+    v_config      regconfig;
+    v_char_values text;
+BEGIN
+    SELECT nt.type, nt.search_config
+    INTO v_type, v_config
+    FROM shop.node_types nt
+    WHERE nt.id = NEW.node_type_id;
+
+    SELECT string_agg(c.title || ' ' || cv.value, ' ')
+    INTO v_char_values
+    FROM shop.characteristic_values cv
+             JOIN shop.characteristics c ON c.id = cv.characteristic_id
+    WHERE cv.node_id = NEW.id
+      AND c.is_visible = true;
+
+    v_config := COALESCE(v_config, 'russian'::regconfig);
+
+    NEW.search_vector :=
+            setweight(to_tsvector(v_config, COALESCE(NEW.title, '')), 'A')
+            || setweight(to_tsvector(v_config, COALESCE(v_type, '')), 'B')
+            || setweight(to_tsvector(v_config, COALESCE(v_char_values, '')), 'C')
+            || setweight(to_tsvector(v_config, COALESCE(NEW.description, '')), 'D');
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- search_vector — производная колонка: её пересчёт (в том числе backfill) не должен менять updated_at.
+CREATE OR REPLACE FUNCTION set_updated_at()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NOT TG_OP = 'UPDATE'
+        OR to_jsonb(NEW) - 'search_vector' - 'updated_at' IS DISTINCT FROM to_jsonb(OLD) - 'search_vector' - 'updated_at' THEN
+        NEW.updated_at := NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Смена названия или конфигурации типа пересчитывает векторы его нод.
+CREATE OR REPLACE FUNCTION fn_node_types_update_nodes()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NEW.type IS DISTINCT FROM OLD.type OR NEW.search_config IS DISTINCT FROM OLD.search_config THEN
+        UPDATE shop.nodes
+        SET search_vector = NULL -- вектор пересчитает tr_update_nodes_search_vector
+        WHERE node_type_id = NEW.id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tr_node_types_update_nodes
+    AFTER UPDATE
+    ON shop.node_types
+    FOR EACH ROW
+EXECUTE PROCEDURE fn_node_types_update_nodes();
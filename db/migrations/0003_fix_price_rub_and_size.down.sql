@@ -0,0 +1,3 @@
+ALTER TABLE shop.size SET SCHEMA public;
+
+ALTER TABLE shop.nodes RENAME COLUMN price_rub TO price_run;
@@ -0,0 +1,18 @@
+-- Базовая схема создавала колонку price_run, а код всегда читал price_rub.
+-- Если колонку уже переименовали вручную, переименование пропускается.
+DO
+$$
+BEGIN
+    IF EXISTS (SELECT 1
+               FROM information_schema.columns
+               WHERE table_schema = 'shop'
+                 AND table_name = 'nodes'
+                 AND column_name = 'price_run') THEN
+        ALTER TABLE shop.nodes RENAME COLUMN price_run TO price_rub;
+    END IF;
+END
+$$;
+
+-- Справочник размеров переносится в схему shop к остальным таблицам.
+-- Внешние ключи shop.stock и shop.order_items на него сохраняются.
+ALTER TABLE IF EXISTS public.size SET SCHEMA shop;
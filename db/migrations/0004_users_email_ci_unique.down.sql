@@ -0,0 +1 @@
+DROP INDEX IF EXISTS shop.users_email_lower_key;
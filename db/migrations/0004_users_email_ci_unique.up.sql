@@ -0,0 +1,4 @@
+-- Email сравнивается без учёта регистра (вход и регистрация ищут по lower(email)),
+-- поэтому уникальность тоже проверяется по lower(email): регистрация больше не делает
+-- предварительную проверку, а полагается на это ограничение.
+CREATE UNIQUE INDEX users_email_lower_key ON shop.users (lower(email));
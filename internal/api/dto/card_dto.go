@@ -22,3 +22,37 @@ type GetCardsByVectorDTO struct {
 	Text  string `json:"text" validate:"required,min=1,max=200"`
 	Limit int    `json:"limit" validate:"required,number,min=1,max=20"`
 }
+
+// UpdateCardDTO — полное обновление карточки (PUT): все поля и набор характеристик заменяются целиком.
+type UpdateCardDTO struct {
+	Title           string    `json:"title" validate:"required,min=1"`
+	NodeDescription *string   `json:"nodeDescription" validate:"omitempty,min=3,max=1000"`
+	NodeTypeId      int       `json:"nodeTypeId" validate:"required,number"`
+	PriceByn        int       `json:"priceByn" validate:"required,number"`
+	PriceRub        int       `json:"priceRub" validate:"required,number"`
+	Images          []string  `json:"images" validate:"required,min=1,dive"`
+	Characteristics []CharDTO `json:"characteristics" validate:"required,min=1,dive"`
+}
+
+// PatchCardDTO — частичное обновление карточки (PATCH): меняются только переданные поля.
+// Если переданы characteristics, заменяются значения только упомянутых в них характеристик.
+type PatchCardDTO struct {
+	Title           *string    `json:"title" validate:"omitempty,min=1"`
+	NodeDescription *string    `json:"nodeDescription" validate:"omitempty,min=3,max=1000"`
+	NodeTypeId      *int       `json:"nodeTypeId" validate:"omitempty,number"`
+	PriceByn        *int       `json:"priceByn" validate:"omitempty,number"`
+	PriceRub        *int       `json:"priceRub" validate:"omitempty,number"`
+	Images          *[]string  `json:"images" validate:"omitempty,min=1,dive"`
+	Characteristics *[]CharDTO `json:"characteristics" validate:"omitempty,min=1,dive"`
+}
+
+// IsEmpty сообщает, что в PATCH-запросе не передано ни одного поля.
+func (d *PatchCardDTO) IsEmpty() bool {
+	return d.Title == nil &&
+		d.NodeDescription == nil &&
+		d.NodeTypeId == nil &&
+		d.PriceByn == nil &&
+		d.PriceRub == nil &&
+		d.Images == nil &&
+		d.Characteristics == nil
+}
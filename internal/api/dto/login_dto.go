@@ -4,3 +4,7 @@ type LoginRequest struct {
 	Email    string `json:"email" validate:"required,custom_email"`
 	Password string `json:"password" validate:"required,min=3"`
 }
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
@@ -1,12 +1,14 @@
 package dto
 
 type CreateNodeTypeRequest struct {
-	Type        string  `json:"type" validate:"required,min=1"`
-	Description *string `json:"description" validate:"omitempty,min=3,max=1000"`
+	Type         string  `json:"type" validate:"required,min=1"`
+	Description  *string `json:"description" validate:"omitempty,min=3,max=1000"`
+	SearchConfig *string `json:"searchConfig" validate:"omitempty,oneof=russian english belarusian simple"`
 }
 
 type UpdateNodeTypeRequest struct {
-	ID          int     `json:"id" validate:"required,number"`
-	Type        string  `json:"type" validate:"required,min=1"`
-	Description *string `json:"description" validate:"omitempty,min=3,max=1000"`
+	ID           int     `json:"id" validate:"required,number"`
+	Type         string  `json:"type" validate:"required,min=1"`
+	Description  *string `json:"description" validate:"omitempty,min=3,max=1000"`
+	SearchConfig *string `json:"searchConfig" validate:"omitempty,oneof=russian english belarusian simple"`
 }
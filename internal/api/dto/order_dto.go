@@ -5,3 +5,8 @@ type OrderDTO struct {
 	Size   *string `json:"description" validate:"omitempty,min=3,max=1000"`
 	Amount int     `json:"amount" validate:"required,number"`
 }
+
+type UpdateOrderStatusRequest struct {
+	Status  string  `json:"status" validate:"required,oneof=new confirmed paid shipped delivered cancelled returned"`
+	Comment *string `json:"comment" validate:"omitempty,max=1000"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+type CreateStockRequest struct {
+	NodeId   int `json:"nodeId" validate:"required,number"`
+	SizeId   int `json:"sizeId" validate:"required,number"`
+	Quantity int `json:"quantity" validate:"number,min=0"`
+}
+
+type UpdateStockRequest struct {
+	ID       int `json:"id" validate:"required,number"`
+	Quantity int `json:"quantity" validate:"number,min=0"`
+}
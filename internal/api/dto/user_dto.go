@@ -5,3 +5,7 @@ type CreateUserRequest struct {
 	Phone    string `json:"phone" validate:"required,min=5"`
 	Password string `json:"password" validate:"required,min=3"`
 }
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin manager customer"`
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"shop/internal/api/dto"
+	"shop/internal/api/middlewares/auth"
+	"shop/internal/service"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+	"shop/pkg/utils"
+)
+
+type authHandler struct {
+	authService service.AuthServiceInterface
+	userService service.UserServiceInterface
+}
+
+type AuthHandlerInterface interface {
+	Login(c *fiber.Ctx) error
+	Register(c *fiber.Ctx) error
+	Me(c *fiber.Ctx) error
+	Refresh(c *fiber.Ctx) error
+	Logout(c *fiber.Ctx) error
+}
+
+func NewAuthHandler(authService service.AuthServiceInterface, userService service.UserServiceInterface) AuthHandlerInterface {
+	return &authHandler{authService: authService, userService: userService}
+}
+
+func (h *authHandler) Login(c *fiber.Ctx) error {
+	body, ok := c.Locals("validatedBody").(dto.LoginRequest)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	tokens, err := h.authService.Login(c.UserContext(), &body)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(tokens)
+}
+
+func (h *authHandler) Register(c *fiber.Ctx) error {
+	body, ok := c.Locals("validatedBody").(dto.CreateUserRequest)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	user, err := h.userService.Register(c.UserContext(), &body)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(user)
+}
+
+func (h *authHandler) Me(c *fiber.Ctx) error {
+	userIdStr, ok := auth.GetUserId(c)
+	if !ok {
+		return http_error.NewHTTPError(fiber.StatusUnauthorized, "Invalid token claims", nil).Send(c)
+	}
+
+	userId, err := utils.StringToInt(userIdStr)
+	if err != nil {
+		return http_error.NewHTTPError(fiber.StatusUnauthorized, "Invalid token claims", nil).Send(c)
+	}
+
+	user, err := h.userService.GetUserById(c.UserContext(), userId)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+func (h *authHandler) Refresh(c *fiber.Ctx) error {
+	body, ok := c.Locals("validatedBody").(dto.RefreshTokenRequest)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	tokens, err := h.authService.Refresh(c.UserContext(), &body)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(tokens)
+}
+
+func (h *authHandler) Logout(c *fiber.Ctx) error {
+	body, ok := c.Locals("validatedBody").(dto.RefreshTokenRequest)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	if err := h.authService.Logout(c.UserContext(), &body); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -9,31 +9,35 @@ import (
 	"shop/pkg/http_error"
 	"shop/pkg/log"
 	"shop/pkg/utils"
-	"strings"
 )
 
-type cardHandler struct{}
+type cardHandler struct {
+	cardService    service.CardServiceInterface
+	suggestService service.SuggestServiceInterface
+}
 
 type CardHandlerInterface interface {
 	GetCardById(c *fiber.Ctx) error
 	GetAllCards(c *fiber.Ctx) error
+	GetCardFacets(c *fiber.Ctx) error
+	Suggest(c *fiber.Ctx) error
 	GetCardsByVector(c *fiber.Ctx) error
 	CreateCard(c *fiber.Ctx) error
+	UpdateCard(c *fiber.Ctx) error
+	PatchCard(c *fiber.Ctx) error
 }
 
-func NewCardHandler() CardHandlerInterface {
-	return &cardHandler{}
+func NewCardHandler(cardService service.CardServiceInterface, suggestService service.SuggestServiceInterface) CardHandlerInterface {
+	return &cardHandler{cardService: cardService, suggestService: suggestService}
 }
 
-var CardHandler = NewCardHandler()
-
 func (h *cardHandler) GetCardById(c *fiber.Ctx) error {
 
 	// Retrieve article ID from context.
 	id := c.Locals("Id")
 	cardIdStr, ok := id.(string)
 	if !ok || cardIdStr == "0" {
-		log.Error("ID is missing in the context")
+		log.FromContext(c.UserContext()).Error("ID is missing in the context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "ID is required", nil).Send(c)
 	}
 
@@ -42,11 +46,12 @@ func (h *cardHandler) GetCardById(c *fiber.Ctx) error {
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, err.Error(), nil).Send(c)
 	}
 
-	card, err := service.CardService.GetCardById(cardId)
+	includeRemoved, _ := c.Locals("includeRemoved").(bool)
+
+	card, err := h.cardService.GetCardById(c.UserContext(), cardId, includeRemoved)
 
 	if err != nil {
-		log.Error("Failed to find card", zap.Int("cardId", cardId), zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to find card", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(card)
@@ -56,80 +61,114 @@ func (h *cardHandler) GetAllCards(c *fiber.Ctx) error {
 	// Извлечение query-параметров пагинации
 	pageNumber, err := utils.StringToInt(c.Query("pageNumber", "1"))
 	if err != nil || pageNumber < 1 {
-		log.Error("Invalid or missing page number in query", zap.Error(err))
+		log.FromContext(c.UserContext()).Error("Invalid or missing page number in query", zap.Error(err))
 		pageNumber = 1
 	}
 
 	pageSize, err := utils.StringToInt(c.Query("pageSize", "50"))
 	if err != nil || pageSize < 1 {
-		log.Error("Invalid or missing page size in query", zap.Error(err))
+		log.FromContext(c.UserContext()).Error("Invalid or missing page size in query", zap.Error(err))
 		pageSize = 50
 	}
 
-	filters := make([]model.CardFilter, 0)
+	sort, ok := c.Locals("cardSort").(string)
+	if !ok {
+		sort = model.CardSortNewest
+	}
 
-	// c.Queries() вернёт map[string]string, где key — это имя параметра, а value — его значение
-	for key, value := range c.Queries() {
-		// Пропускаем параметры пагинации
-		if key == "pageNumber" || key == "pageSize" {
-			continue
-		}
+	filter, ok := c.Locals("cardFilter").(*model.CardFilter)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve card filter from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
 
-		// Проверяем, содержит ли значение запятые
-		if strings.Contains(value, ",") {
-			parts := strings.Split(value, ",")
-			for _, part := range parts {
-				part := strings.TrimSpace(part) // убираем пробелы вокруг
-				if part != "" {
-					// Каждый элемент добавляем отдельным CardFilter
-					filters = append(filters, model.CardFilter{
-						Key:    key,
-						Values: part,
-					})
-				}
-			}
-		} else {
-			// Если запятых нет — добавляем значение как есть
-			filters = append(filters, model.CardFilter{
-				Key:    key,
-				Values: value,
-			})
+	// Непрозрачный курсор keyset-пагинации; если передан, pageNumber игнорируется.
+	// Курсор действителен только для той сортировки и валюты, с которыми он был получен.
+	var cursor *model.CardCursor
+	if rawCursor := c.Query("cursor"); rawCursor != "" {
+		cursor, err = utils.DecodeCursor[model.CardCursor](rawCursor)
+		if err != nil || cursor.Sort != sort || cursor.Currency != filter.Currency {
+			log.FromContext(c.UserContext()).Warn("Invalid cards cursor", zap.String("cursor", rawCursor))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid cursor", nil).Send(c)
 		}
 	}
 
 	// Логирование параметров для отладки
-	log.Info("Fetching cards with filters",
-		zap.Any("filters", filters),
+	log.FromContext(c.UserContext()).Info("Fetching cards with filters",
+		zap.Any("filter", filter),
 	)
 
 	// Вызов сервиса для получения карт с учетом фильтров
-	cards, err := service.CardService.GetAllCards(pageNumber, pageSize, &filters)
+	includeRemoved, _ := c.Locals("includeRemoved").(bool)
+
+	cards, err := h.cardService.GetAllCards(c.UserContext(), pageNumber, pageSize, cursor, filter, sort, includeRemoved)
 	if err != nil {
-		log.Error("Failed to fetch paginated cards with filters", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch cards", nil).Send(c)
+		return err
 	}
 
 	// Возврат результата
 	return c.Status(fiber.StatusOK).JSON(cards)
 }
 
+// GetCardFacets возвращает фасеты для тех же фильтров, что и список карточек.
+// Ширина ценового интервала задаётся query-параметром priceStep.
+func (h *cardHandler) GetCardFacets(c *fiber.Ctx) error {
+	priceStep := model.DefaultFacetPriceStep
+	if rawPriceStep := c.Query("priceStep"); rawPriceStep != "" {
+		var err error
+		priceStep, err = utils.StringToInt(rawPriceStep)
+		if err != nil || priceStep < 1 {
+			log.FromContext(c.UserContext()).Warn("Invalid priceStep parameter", zap.String("priceStep", rawPriceStep))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid priceStep parameter", nil).Send(c)
+		}
+	}
+
+	filter, ok := c.Locals("cardFilter").(*model.CardFilter)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve card filter from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	includeRemoved, _ := c.Locals("includeRemoved").(bool)
+
+	facets, err := h.cardService.GetCardFacets(c.UserContext(), filter, includeRemoved, priceStep)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(facets)
+}
+
+// Suggest возвращает подсказки для строки поиска, устойчивые к опечаткам.
+func (h *cardHandler) Suggest(c *fiber.Ctx) error {
+	query, okQuery := c.Locals("suggestQuery").(string)
+	limit, okLimit := c.Locals("suggestLimit").(int)
+	if !okQuery || !okLimit {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve suggest parameters from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	suggestions, err := h.suggestService.Suggest(c.UserContext(), query, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(suggestions)
+}
+
 func (h *cardHandler) CreateCard(c *fiber.Ctx) error {
 	reqInterface := c.Locals("validatedBody")
 
 	body, ok := reqInterface.(dto.CreateCardDTO)
 	if !ok {
-		log.Error("Failed to retrieve validated request from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
 
 	// 3. Вызываем метод сервиса
-	newID, err := service.CardService.CreateCard(&body)
+	newID, err := h.cardService.CreateCard(c.UserContext(), &body)
 	if err != nil {
-		log.Error("Failed to create card", zap.Error(err))
-		// При желании можно вернуть подробную ошибку
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	// 4. Возвращаем успешный результат
@@ -143,15 +182,68 @@ func (h *cardHandler) GetCardsByVector(c *fiber.Ctx) error {
 
 	body, ok := reqInterface.(dto.GetCardsByVectorDTO)
 	if !ok {
-		log.Error("Failed to retrieve validated request from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
 
-	cards, err := service.CardService.GetCardsByVector(&body)
+	includeRemoved, _ := c.Locals("includeRemoved").(bool)
+
+	cards, err := h.cardService.GetCardsByVector(c.UserContext(), &body, includeRemoved)
 	if err != nil {
-		log.Error("Failed to get cards by vector", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to get cards", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(cards)
 }
+
+func (h *cardHandler) UpdateCard(c *fiber.Ctx) error {
+	cardIdStr, ok := c.Locals("Id").(string)
+	if !ok || cardIdStr == "0" {
+		log.FromContext(c.UserContext()).Error("ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "ID is required", nil).Send(c)
+	}
+
+	cardId, err := utils.StringToInt(cardIdStr)
+	if err != nil {
+		return http_error.NewHTTPError(fiber.StatusBadRequest, err.Error(), nil).Send(c)
+	}
+
+	body, ok := c.Locals("validatedBody").(dto.UpdateCardDTO)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	card, err := h.cardService.UpdateCard(c.UserContext(), cardId, &body)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(card)
+}
+
+func (h *cardHandler) PatchCard(c *fiber.Ctx) error {
+	cardIdStr, ok := c.Locals("Id").(string)
+	if !ok || cardIdStr == "0" {
+		log.FromContext(c.UserContext()).Error("ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "ID is required", nil).Send(c)
+	}
+
+	cardId, err := utils.StringToInt(cardIdStr)
+	if err != nil {
+		return http_error.NewHTTPError(fiber.StatusBadRequest, err.Error(), nil).Send(c)
+	}
+
+	body, ok := c.Locals("validatedBody").(dto.PatchCardDTO)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	card, err := h.cardService.PatchCard(c.UserContext(), cardId, &body)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(card)
+}
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"go.uber.org/zap"
 	"shop/internal/api/dto"
 	"shop/internal/service"
 	"shop/pkg/http_error"
@@ -10,7 +9,9 @@ import (
 	"shop/pkg/utils"
 )
 
-type charDefaultValueHandler struct{}
+type charDefaultValueHandler struct {
+	charDefaultValueService service.CharDefaultValueServiceInterface
+}
 
 type CharDefaultValueHandlerInterface interface {
 	GetAllDefValue(c *fiber.Ctx) error
@@ -20,32 +21,29 @@ type CharDefaultValueHandlerInterface interface {
 	DeleteDefValue(c *fiber.Ctx) error
 }
 
-func NewCharDefaultValueHandler() CharDefaultValueHandlerInterface {
-	return &charDefaultValueHandler{}
+func NewCharDefaultValueHandler(charDefaultValueService service.CharDefaultValueServiceInterface) CharDefaultValueHandlerInterface {
+	return &charDefaultValueHandler{charDefaultValueService: charDefaultValueService}
 }
 
-var CharDefaultValueHandler = NewCharDefaultValueHandler()
-
 func (h *charDefaultValueHandler) GetAllDefValue(c *fiber.Ctx) error {
 	pageNumberInterface := c.Locals("pageNumber")
 	pageSizeInterface := c.Locals("pageSize")
 
 	pageNumber, ok := pageNumberInterface.(int)
 	if !ok {
-		log.Error("Failed to retrieve page number from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve page number from context")
 		pageNumber = 1
 	}
 
 	pageSize, ok := pageSizeInterface.(int)
 	if !ok {
-		log.Error("Failed to retrieve page char_default_value from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve page char_default_value from context")
 		pageSize = 100
 	}
 
-	users, err := service.CharDefaultValueService.GetAllDefValue(pageNumber, pageSize)
+	users, err := h.charDefaultValueService.GetAllDefValue(c.UserContext(), pageNumber, pageSize)
 	if err != nil {
-		log.Error("Failed to fetch paginated char_default_value", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch char_default_value", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(users)
@@ -56,14 +54,13 @@ func (h *charDefaultValueHandler) CreateDefValue(c *fiber.Ctx) error {
 
 	body, ok := reqInterface.(dto.CreateCharDefValueRequest)
 	if !ok {
-		log.Error("Failed to retrieve validated request from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
 
-	size, err := service.CharDefaultValueService.CreateDefValue(&body)
+	size, err := h.charDefaultValueService.CreateDefValue(c.UserContext(), &body)
 	if err != nil {
-		log.Error("Failed to create char_default_value", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to create char_default_value", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(size)
@@ -74,14 +71,13 @@ func (h *charDefaultValueHandler) UpdateDefValue(c *fiber.Ctx) error {
 
 	body, ok := reqDto.(dto.UpdateCharDefValueRequest)
 	if !ok {
-		log.Error("Failed to retrieve validated request from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
 
-	size, err := service.CharDefaultValueService.UpdateDefValue(&body)
+	size, err := h.charDefaultValueService.UpdateDefValue(c.UserContext(), &body)
 	if err != nil {
-		log.Error("Failed to create char_default_value", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to create char_default_value", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(size)
@@ -92,7 +88,7 @@ func (h *charDefaultValueHandler) DeleteDefValue(c *fiber.Ctx) error {
 
 	IdStr, ok := idContext.(string)
 	if !ok || IdStr == "0" {
-		log.Error("ID is missing in the context")
+		log.FromContext(c.UserContext()).Error("ID is missing in the context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "ID is required", nil).Send(c)
 	}
 
@@ -101,10 +97,9 @@ func (h *charDefaultValueHandler) DeleteDefValue(c *fiber.Ctx) error {
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, err.Error(), nil).Send(c)
 	}
 
-	err = service.CharDefaultValueService.DeleteDefValueById(id)
+	err = h.charDefaultValueService.DeleteDefValueById(c.UserContext(), id)
 	if err != nil {
-		log.Error("Failed to remove char_default_value", zap.Int("id", id), zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to remove char_default_value", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"id": id})
@@ -113,7 +108,7 @@ func (h *charDefaultValueHandler) DeleteDefValue(c *fiber.Ctx) error {
 func (h *charDefaultValueHandler) GetDefValueById(c *fiber.Ctx) error {
 	idStr, ok := c.Locals("Id").(string)
 	if !ok || idStr == "0" {
-		log.Error("ID is missing in the context")
+		log.FromContext(c.UserContext()).Error("ID is missing in the context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "ID is required", nil).Send(c)
 	}
 
@@ -122,10 +117,9 @@ func (h *charDefaultValueHandler) GetDefValueById(c *fiber.Ctx) error {
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, err.Error(), nil).Send(c)
 	}
 
-	card, err := service.CharDefaultValueService.GetDefValueById(defValId)
+	card, err := h.charDefaultValueService.GetDefValueById(c.UserContext(), defValId)
 	if err != nil {
-		log.Error("Failed to find char_default_value", zap.Int("defValId", defValId), zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to find card", nil).Send(c)
+		return err
 	}
 
 	return c.JSON(card)
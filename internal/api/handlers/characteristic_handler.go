@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"go.uber.org/zap"
 	"shop/internal/api/dto"
 	"shop/internal/service"
 	"shop/pkg/http_error"
@@ -10,7 +9,9 @@ import (
 	"shop/pkg/utils"
 )
 
-type characteristicHandler struct{}
+type characteristicHandler struct {
+	characteristicService service.CharacteristicServiceInterface
+}
 
 type CharacteristicHandlerInterface interface {
 	GetAllCharacteristics(c *fiber.Ctx) error
@@ -20,32 +21,29 @@ type CharacteristicHandlerInterface interface {
 	UpdateCharacteristic(c *fiber.Ctx) error
 }
 
-func NewCharacteristicHandler() CharacteristicHandlerInterface {
-	return &characteristicHandler{}
+func NewCharacteristicHandler(characteristicService service.CharacteristicServiceInterface) CharacteristicHandlerInterface {
+	return &characteristicHandler{characteristicService: characteristicService}
 }
 
-var CharacteristicHandler = NewCharacteristicHandler()
-
 func (h *characteristicHandler) GetAllCharacteristics(c *fiber.Ctx) error {
 	pageNumberInterface := c.Locals("pageNumber")
 	pageSizeInterface := c.Locals("pageSize")
 
 	pageNumber, ok := pageNumberInterface.(int)
 	if !ok {
-		log.Error("Failed to retrieve page number from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve page number from context")
 		pageNumber = 1
 	}
 
 	pageSize, ok := pageSizeInterface.(int)
 	if !ok {
-		log.Error("Failed to retrieve page characteristic from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve page characteristic from context")
 		pageSize = 100
 	}
 
-	users, err := service.CharacteristicService.GetAllCharacteristic(pageNumber, pageSize)
+	users, err := h.characteristicService.GetAllCharacteristic(c.UserContext(), pageNumber, pageSize)
 	if err != nil {
-		log.Error("Failed to fetch paginated characteristics", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch characteristic", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(users)
@@ -56,14 +54,15 @@ func (h *characteristicHandler) GetCharForFilters(c *fiber.Ctx) error {
 	nodeTypeId, ok := nodeTypeIdInterface.(int)
 
 	if !ok {
-		log.Error("Failed to retrieve nodeTypeId from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve nodeTypeId from context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch filters", nil).Send(c)
 	}
 
-	filters, err := service.CharacteristicService.GetCharForFilters(nodeTypeId)
+	includeRemoved, _ := c.Locals("includeRemoved").(bool)
+
+	filters, err := h.characteristicService.GetCharForFilters(c.UserContext(), nodeTypeId, includeRemoved)
 	if err != nil {
-		log.Error("Failed to fetch paginated filters", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch filters", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(filters)
@@ -74,14 +73,13 @@ func (h *characteristicHandler) CreateCharacteristic(c *fiber.Ctx) error {
 
 	body, ok := reqInterface.(dto.CreateCharacteristicRequest)
 	if !ok {
-		log.Error("Failed to retrieve validated request from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
 
-	size, err := service.CharacteristicService.CreateCharacteristic(&body)
+	size, err := h.characteristicService.CreateCharacteristic(c.UserContext(), &body)
 	if err != nil {
-		log.Error("Failed to create characteristic", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to create characteristic", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(size)
@@ -92,14 +90,13 @@ func (h *characteristicHandler) UpdateCharacteristic(c *fiber.Ctx) error {
 
 	body, ok := reqDto.(dto.UpdateCharacteristicRequest)
 	if !ok {
-		log.Error("Failed to retrieve validated request from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
 
-	size, err := service.CharacteristicService.UpdateCharacteristic(&body)
+	size, err := h.characteristicService.UpdateCharacteristic(c.UserContext(), &body)
 	if err != nil {
-		log.Error("Failed to create characteristic", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to create characteristic", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(size)
@@ -111,7 +108,7 @@ func (h *characteristicHandler) DeleteCharacteristic(c *fiber.Ctx) error {
 	id := c.Locals("Id")
 	sizeIdStr, ok := id.(string)
 	if !ok || sizeIdStr == "0" {
-		log.Error("ID is missing in the context")
+		log.FromContext(c.UserContext()).Error("ID is missing in the context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "ID is required", nil).Send(c)
 	}
 
@@ -120,10 +117,9 @@ func (h *characteristicHandler) DeleteCharacteristic(c *fiber.Ctx) error {
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, err.Error(), nil).Send(c)
 	}
 
-	err = service.CharacteristicService.DeleteCharacteristic(sizeId)
+	err = h.characteristicService.DeleteCharacteristic(c.UserContext(), sizeId)
 	if err != nil {
-		log.Error("Failed to remove size", zap.Int("sizeId", sizeId), zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to remove user", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"id": sizeId})
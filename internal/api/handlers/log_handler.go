@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"shop/internal/api/dto"
+	"shop/internal/api/middlewares/auth"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+)
+
+type logHandler struct{}
+
+type LogHandlerInterface interface {
+	GetLogLevel(c *fiber.Ctx) error
+	UpdateLogLevel(c *fiber.Ctx) error
+}
+
+func NewLogHandler() LogHandlerInterface {
+	return &logHandler{}
+}
+
+// GetLogLevel возвращает текущий уровень логирования.
+func (h *logHandler) GetLogLevel(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"level": log.Level().String()})
+}
+
+// UpdateLogLevel меняет уровень логирования без перезапуска. Изменение действует до перезапуска процесса.
+func (h *logHandler) UpdateLogLevel(c *fiber.Ctx) error {
+	body, ok := c.Locals("validatedBody").(dto.UpdateLogLevelRequest)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	level, err := zapcore.ParseLevel(body.Level)
+	if err != nil {
+		return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid level", nil).Send(c)
+	}
+
+	previous := log.Level()
+	log.SetLevel(level)
+
+	// Запись аудита: кто изменил уровень. Она пишется на уровне не ниже warn и не ниже старого
+	// и нового уровней, чтобы её не отбросил ни один из них
+	changedBy, _ := auth.GetUserId(c)
+	log.FromContext(c.UserContext()).Log(max(zapcore.WarnLevel, previous, level), "Log level changed",
+		zap.Bool("audit", true),
+		zap.String("changed_by", changedBy),
+		zap.Stringer("from", previous),
+		zap.Stringer("to", level))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"level": level.String()})
+}
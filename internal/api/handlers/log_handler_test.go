@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http/httptest"
+	"os"
+	"shop/internal/api/dto"
+	"shop/pkg/log"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	log.InitLogger()
+	os.Exit(m.Run())
+}
+
+func TestUpdateLogLevelAudit(t *testing.T) {
+	initial := log.Level()
+	t.Cleanup(func() { log.SetLevel(initial) })
+
+	tests := []struct {
+		name     string
+		from     zapcore.Level
+		to       string
+		logLevel zapcore.Level
+	}{
+		{"more verbose", zapcore.InfoLevel, "debug", zapcore.WarnLevel},
+		{"less verbose", zapcore.InfoLevel, "warn", zapcore.WarnLevel},
+		{"errors only", zapcore.DebugLevel, "error", zapcore.ErrorLevel},
+		{"back from errors only", zapcore.ErrorLevel, "info", zapcore.ErrorLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log.SetLevel(tt.from)
+			core, logs := observer.New(zapcore.DebugLevel)
+
+			app := fiber.New()
+			app.Put("/log-level", func(c *fiber.Ctx) error {
+				c.SetUserContext(log.WithLogger(c.UserContext(), zap.New(core)))
+				c.Locals("userId", "42")
+				c.Locals("validatedBody", dto.UpdateLogLevelRequest{Level: tt.to})
+				return c.Next()
+			}, NewLogHandler().UpdateLogLevel)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodPut, "/log-level", nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, tt.to, log.Level().String())
+
+			entries := logs.FilterMessage("Log level changed").All()
+			if assert.Len(t, entries, 1) {
+				assert.Equal(t, tt.logLevel, entries[0].Level)
+				assert.Equal(t, map[string]interface{}{
+					"audit":      true,
+					"changed_by": "42",
+					"from":       tt.from.String(),
+					"to":         tt.to,
+				}, entries[0].ContextMap())
+			}
+		})
+	}
+}
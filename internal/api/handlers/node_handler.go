@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"go.uber.org/zap"
 	"shop/internal/api/dto"
 	"shop/internal/service"
 	"shop/pkg/http_error"
@@ -10,41 +9,42 @@ import (
 	"shop/pkg/utils"
 )
 
-type nodeHandler struct{}
+type nodeHandler struct {
+	nodeService service.NodeServiceInterface
+}
 
 type NodeHandlerInterface interface {
 	GetAllNode(c *fiber.Ctx) error
 	CreateNode(c *fiber.Ctx) error
 	DeleteNode(c *fiber.Ctx) error
 	UpdateNode(c *fiber.Ctx) error
+	RestoreNode(c *fiber.Ctx) error
+	PurgeRemovedNodes(c *fiber.Ctx) error
 }
 
-func NewNodeHandler() NodeHandlerInterface {
-	return &nodeHandler{}
+func NewNodeHandler(nodeService service.NodeServiceInterface) NodeHandlerInterface {
+	return &nodeHandler{nodeService: nodeService}
 }
 
-var NodeHandler = NewNodeHandler()
-
 func (h *nodeHandler) GetAllNode(c *fiber.Ctx) error {
 	pageNumberInterface := c.Locals("pageNumber")
 	pageSizeInterface := c.Locals("pageSize")
 
 	pageNumber, ok := pageNumberInterface.(int)
 	if !ok {
-		log.Error("Failed to retrieve page number from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve page number from context")
 		pageNumber = 1
 	}
 
 	pageSize, ok := pageSizeInterface.(int)
 	if !ok {
-		log.Error("Failed to retrieve page node from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve page node from context")
 		pageSize = 100
 	}
 
-	users, err := service.NodeService.GetAllNode(pageNumber, pageSize)
+	users, err := h.nodeService.GetAllNode(c.UserContext(), pageNumber, pageSize)
 	if err != nil {
-		log.Error("Failed to fetch paginated node", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch node", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(users)
@@ -55,13 +55,12 @@ func (h *nodeHandler) CreateNode(c *fiber.Ctx) error {
 
 	body, ok := reqInterface.(dto.CreateNodeRequest)
 	if !ok {
-		log.Error("Failed to retrieve validated request from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
-	node, err := service.NodeService.CreateNode(&body)
+	node, err := h.nodeService.CreateNode(c.UserContext(), &body)
 	if err != nil {
-		log.Error("Failed to create node", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to create node", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(node)
@@ -72,14 +71,13 @@ func (h *nodeHandler) UpdateNode(c *fiber.Ctx) error {
 
 	body, ok := reqDto.(dto.UpdateNodeRequest)
 	if !ok {
-		log.Error("Failed to retrieve validated request from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
 
-	size, err := service.NodeService.UpdateNode(&body)
+	size, err := h.nodeService.UpdateNode(c.UserContext(), &body)
 	if err != nil {
-		log.Error("Failed to create node", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to create node", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(size)
@@ -90,7 +88,7 @@ func (h *nodeHandler) DeleteNode(c *fiber.Ctx) error {
 	id := c.Locals("Id")
 	nodeIdIdStr, ok := id.(string)
 	if !ok || nodeIdIdStr == "0" {
-		log.Error("ID is missing in the context")
+		log.FromContext(c.UserContext()).Error("ID is missing in the context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "ID is required", nil).Send(c)
 	}
 
@@ -99,11 +97,44 @@ func (h *nodeHandler) DeleteNode(c *fiber.Ctx) error {
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, err.Error(), nil).Send(c)
 	}
 
-	err = service.NodeService.DeleteNode(nodeId)
+	err = h.nodeService.DeleteNode(c.UserContext(), nodeId)
 	if err != nil {
-		log.Error("Failed to remove node_type", zap.Int("nodeId", nodeId), zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to remove user", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"id": nodeId})
 }
+
+func (h *nodeHandler) RestoreNode(c *fiber.Ctx) error {
+	nodeIdStr, ok := c.Locals("Id").(string)
+	if !ok || nodeIdStr == "0" {
+		log.FromContext(c.UserContext()).Error("ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "ID is required", nil).Send(c)
+	}
+
+	nodeId, err := utils.StringToInt(nodeIdStr)
+	if err != nil {
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, err.Error(), nil).Send(c)
+	}
+
+	node, err := h.nodeService.RestoreNode(c.UserContext(), nodeId)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(node)
+}
+
+func (h *nodeHandler) PurgeRemovedNodes(c *fiber.Ctx) error {
+	var olderThanDays *int
+	if days, ok := c.Locals("olderThanDays").(int); ok {
+		olderThanDays = &days
+	}
+
+	result, err := h.nodeService.PurgeRemovedNodes(c.UserContext(), olderThanDays)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
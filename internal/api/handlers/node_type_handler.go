@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"go.uber.org/zap"
 	"shop/internal/api/dto"
 	"shop/internal/service"
 	"shop/pkg/http_error"
@@ -10,7 +9,9 @@ import (
 	"shop/pkg/utils"
 )
 
-type nodeTypeHandler struct{}
+type nodeTypeHandler struct {
+	nodeTypeService service.NodeTypeServiceInterface
+}
 
 type NodeTypeHandlerInterface interface {
 	GetAllNodeType(c *fiber.Ctx) error
@@ -19,32 +20,29 @@ type NodeTypeHandlerInterface interface {
 	UpdateNodeType(c *fiber.Ctx) error
 }
 
-func NewNodeTypeHandler() NodeTypeHandlerInterface {
-	return &nodeTypeHandler{}
+func NewNodeTypeHandler(nodeTypeService service.NodeTypeServiceInterface) NodeTypeHandlerInterface {
+	return &nodeTypeHandler{nodeTypeService: nodeTypeService}
 }
 
-var NodeTypeHandler = NewNodeTypeHandler()
-
 func (h *nodeTypeHandler) GetAllNodeType(c *fiber.Ctx) error {
 	pageNumberInterface := c.Locals("pageNumber")
 	pageSizeInterface := c.Locals("pageSize")
 
 	pageNumber, ok := pageNumberInterface.(int)
 	if !ok {
-		log.Error("Failed to retrieve page number from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve page number from context")
 		pageNumber = 1
 	}
 
 	pageSize, ok := pageSizeInterface.(int)
 	if !ok {
-		log.Error("Failed to retrieve page node_type from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve page node_type from context")
 		pageSize = 100
 	}
 
-	users, err := service.NodeTypeService.GetAllNodeType(pageNumber, pageSize)
+	users, err := h.nodeTypeService.GetAllNodeType(c.UserContext(), pageNumber, pageSize)
 	if err != nil {
-		log.Error("Failed to fetch paginated node_type", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch node_type", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(users)
@@ -55,14 +53,13 @@ func (h *nodeTypeHandler) CreateNodeType(c *fiber.Ctx) error {
 
 	body, ok := reqInterface.(dto.CreateNodeTypeRequest)
 	if !ok {
-		log.Error("Failed to retrieve validated request from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
 
-	size, err := service.NodeTypeService.CreateNodeType(&body)
+	size, err := h.nodeTypeService.CreateNodeType(c.UserContext(), &body)
 	if err != nil {
-		log.Error("Failed to create node_type", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to create node_type", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(size)
@@ -73,14 +70,13 @@ func (h *nodeTypeHandler) UpdateNodeType(c *fiber.Ctx) error {
 
 	body, ok := reqDto.(dto.UpdateNodeTypeRequest)
 	if !ok {
-		log.Error("Failed to retrieve validated request from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
 
-	size, err := service.NodeTypeService.UpdateNodeType(&body)
+	size, err := h.nodeTypeService.UpdateNodeType(c.UserContext(), &body)
 	if err != nil {
-		log.Error("Failed to create node_type", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to create node_type", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(size)
@@ -92,7 +88,7 @@ func (h *nodeTypeHandler) DeleteNodeType(c *fiber.Ctx) error {
 	id := c.Locals("Id")
 	nodeTypeIdStr, ok := id.(string)
 	if !ok || nodeTypeIdStr == "0" {
-		log.Error("ID is missing in the context")
+		log.FromContext(c.UserContext()).Error("ID is missing in the context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "ID is required", nil).Send(c)
 	}
 
@@ -101,10 +97,9 @@ func (h *nodeTypeHandler) DeleteNodeType(c *fiber.Ctx) error {
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, err.Error(), nil).Send(c)
 	}
 
-	err = service.NodeTypeService.DeleteNodeType(nodeTypeId)
+	err = h.nodeTypeService.DeleteNodeType(c.UserContext(), nodeTypeId)
 	if err != nil {
-		log.Error("Failed to remove node_type", zap.Int("nodeTypeId", nodeTypeId), zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to remove user", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"id": nodeTypeId})
@@ -2,38 +2,177 @@ package handlers
 
 import (
 	"shop/internal/api/dto"
+	"shop/internal/api/middlewares/auth"
+	"shop/internal/model"
+	"shop/internal/service"
 	"shop/pkg/http_error"
 	"shop/pkg/log"
+	"shop/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/google/uuid"
 )
 
-type orderHandler struct{}
+type orderHandler struct {
+	orderService service.OrderServiceInterface
+}
 
 type OrderHandlerInterface interface {
 	CreateOrder(c *fiber.Ctx) error
+	GetOrderById(c *fiber.Ctx) error
+	GetAllOrders(c *fiber.Ctx) error
+	UpdateOrderStatus(c *fiber.Ctx) error
+	GetOrderStatusHistory(c *fiber.Ctx) error
 }
 
-func NewOrderHandler() OrderHandlerInterface {
-	return &orderHandler{}
+func NewOrderHandler(orderService service.OrderServiceInterface) OrderHandlerInterface {
+	return &orderHandler{orderService: orderService}
 }
 
-var OrderHandler = NewOrderHandler()
-
 func (h *orderHandler) CreateOrder(c *fiber.Ctx) error {
 	reqInterface := c.Locals("validatedBody")
 
-	_, ok := reqInterface.([]dto.OrderDTO)
+	body, ok := reqInterface.([]dto.OrderDTO)
 	if !ok {
-		log.Error("Failed to retrieve validated request from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
 
-	// Генерируем ID для каждого заказа
+	order, err := h.orderService.CreateOrder(c.UserContext(), &body, currentUserId(c))
+	if err != nil {
+		return err
+	}
 
-	orderId := uuid.New().String()
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"orderId": orderId,
+		"orderId": order.ID,
+		"order":   order,
 	})
 }
+
+func (h *orderHandler) GetOrderById(c *fiber.Ctx) error {
+	orderId, ok := c.Locals("Id").(string)
+	if !ok || orderId == "" {
+		log.FromContext(c.UserContext()).Error("ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "ID is required", nil).Send(c)
+	}
+
+	order, err := h.orderService.GetOrderById(c.UserContext(), orderId)
+	if err != nil {
+		return err
+	}
+
+	if !canReadOrder(c, order.UserId) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Order not found", nil).Send(c)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(order)
+}
+
+func (h *orderHandler) GetAllOrders(c *fiber.Ctx) error {
+	pageNumber, ok := c.Locals("pageNumber").(int)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve page number from context")
+		pageNumber = 1
+	}
+
+	pageSize, ok := c.Locals("pageSize").(int)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve page size from context")
+		pageSize = 100
+	}
+
+	filter, ok := c.Locals("orderFilter").(*model.OrderFilter)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve order filter from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	// Без права orders:read:any пользователь видит только свои заказы
+	if !auth.HasPermission(c, model.PermOrdersReadAny) {
+		userId := currentUserId(c)
+		if userId == nil {
+			return http_error.NewHTTPError(fiber.StatusUnauthorized, "Invalid token claims", nil).Send(c)
+		}
+		filter.UserId = userId
+	}
+
+	orders, err := h.orderService.GetAllOrders(c.UserContext(), pageNumber, pageSize, filter)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(orders)
+}
+
+func (h *orderHandler) UpdateOrderStatus(c *fiber.Ctx) error {
+	orderId, ok := c.Locals("Id").(string)
+	if !ok || orderId == "" {
+		log.FromContext(c.UserContext()).Error("ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "ID is required", nil).Send(c)
+	}
+
+	body, ok := c.Locals("validatedBody").(dto.UpdateOrderStatusRequest)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	// Автор изменения известен, только если запрос прошёл аутентификацию
+	var changedBy *string
+	if userId, ok := c.Locals("userId").(string); ok && userId != "" {
+		changedBy = &userId
+	}
+
+	order, err := h.orderService.UpdateOrderStatus(c.UserContext(), orderId, &body, changedBy)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(order)
+}
+
+func (h *orderHandler) GetOrderStatusHistory(c *fiber.Ctx) error {
+	orderId, ok := c.Locals("Id").(string)
+	if !ok || orderId == "" {
+		log.FromContext(c.UserContext()).Error("ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "ID is required", nil).Send(c)
+	}
+
+	if !auth.HasPermission(c, model.PermOrdersReadAny) {
+		order, err := h.orderService.GetOrderById(c.UserContext(), orderId)
+		if err != nil || !canReadOrder(c, order.UserId) {
+			return http_error.NewHTTPError(fiber.StatusNotFound, "Order not found", nil).Send(c)
+		}
+	}
+
+	history, err := h.orderService.GetOrderStatusHistory(c.UserContext(), orderId)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(history)
+}
+
+// currentUserId возвращает id аутентифицированного пользователя или nil для анонимного запроса.
+func currentUserId(c *fiber.Ctx) *int {
+	userIdStr, ok := auth.GetUserId(c)
+	if !ok || userIdStr == "" {
+		return nil
+	}
+
+	userId, err := utils.StringToInt(userIdStr)
+	if err != nil {
+		return nil
+	}
+	return &userId
+}
+
+// canReadOrder сообщает, может ли пользователь запроса видеть заказ владельца ownerId.
+// Чужие заказы доступны только с правом orders:read:any.
+func canReadOrder(c *fiber.Ctx, ownerId *int) bool {
+	if auth.HasPermission(c, model.PermOrdersReadAny) {
+		return true
+	}
+
+	userId := currentUserId(c)
+	return userId != nil && ownerId != nil && *userId == *ownerId
+}
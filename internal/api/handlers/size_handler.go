@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"go.uber.org/zap"
 	"shop/internal/api/dto"
 	"shop/internal/service"
 	"shop/pkg/http_error"
@@ -10,7 +9,9 @@ import (
 	"shop/pkg/utils"
 )
 
-type sizeHandler struct{}
+type sizeHandler struct {
+	sizeService service.SizeServiceInterface
+}
 
 type SizeHandlerInterface interface {
 	GetAllSizes(c *fiber.Ctx) error
@@ -19,32 +20,29 @@ type SizeHandlerInterface interface {
 	UpdateSize(c *fiber.Ctx) error
 }
 
-func NewSizeHandler() SizeHandlerInterface {
-	return &sizeHandler{}
+func NewSizeHandler(sizeService service.SizeServiceInterface) SizeHandlerInterface {
+	return &sizeHandler{sizeService: sizeService}
 }
 
-var SizeHandler = NewSizeHandler()
-
 func (h *sizeHandler) GetAllSizes(c *fiber.Ctx) error { // Retrieve pagination parameters from context.
 	pageNumberInterface := c.Locals("pageNumber")
 	pageSizeInterface := c.Locals("pageSize")
 
 	pageNumber, ok := pageNumberInterface.(int)
 	if !ok {
-		log.Error("Failed to retrieve page number from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve page number from context")
 		pageNumber = 1
 	}
 
 	pageSize, ok := pageSizeInterface.(int)
 	if !ok {
-		log.Error("Failed to retrieve page size from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve page size from context")
 		pageSize = 100
 	}
 
-	users, err := service.SizeService.GetAllSizes(pageNumber, pageSize)
+	users, err := h.sizeService.GetAllSizes(c.UserContext(), pageNumber, pageSize)
 	if err != nil {
-		log.Error("Failed to fetch paginated sizes", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch sizes", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(users)
@@ -55,14 +53,13 @@ func (h *sizeHandler) CreateSize(c *fiber.Ctx) error {
 
 	body, ok := reqInterface.(dto.CreateSizeRequest)
 	if !ok {
-		log.Error("Failed to retrieve validated request from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
 
-	size, err := service.SizeService.CreateSize(&body)
+	size, err := h.sizeService.CreateSize(c.UserContext(), &body)
 	if err != nil {
-		log.Error("Failed to create size", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to create size", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(size)
@@ -73,14 +70,13 @@ func (h *sizeHandler) UpdateSize(c *fiber.Ctx) error {
 
 	body, ok := reqDto.(dto.UpdateSizeRequest)
 	if !ok {
-		log.Error("Failed to retrieve validated request from context")
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
 
-	size, err := service.SizeService.UpdateSize(&body)
+	size, err := h.sizeService.UpdateSize(c.UserContext(), &body)
 	if err != nil {
-		log.Error("Failed to create size", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to create size", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(size)
@@ -92,7 +88,7 @@ func (h *sizeHandler) DeleteSize(c *fiber.Ctx) error {
 	id := c.Locals("Id")
 	sizeIdStr, ok := id.(string)
 	if !ok || sizeIdStr == "0" {
-		log.Error("ID is missing in the context")
+		log.FromContext(c.UserContext()).Error("ID is missing in the context")
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "ID is required", nil).Send(c)
 	}
 
@@ -101,10 +97,9 @@ func (h *sizeHandler) DeleteSize(c *fiber.Ctx) error {
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, err.Error(), nil).Send(c)
 	}
 
-	err = service.SizeService.DeleteSize(sizeId)
+	err = h.sizeService.DeleteSize(c.UserContext(), sizeId)
 	if err != nil {
-		log.Error("Failed to remove size", zap.Int("sizeId", sizeId), zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to remove user", nil).Send(c)
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"id": sizeId})
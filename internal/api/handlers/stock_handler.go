@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"shop/internal/api/dto"
+	"shop/internal/service"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+	"shop/pkg/utils"
+)
+
+type stockHandler struct {
+	stockService service.StockServiceInterface
+}
+
+type StockHandlerInterface interface {
+	GetAllStock(c *fiber.Ctx) error
+	CreateStock(c *fiber.Ctx) error
+	UpdateStock(c *fiber.Ctx) error
+	DeleteStock(c *fiber.Ctx) error
+}
+
+func NewStockHandler(stockService service.StockServiceInterface) StockHandlerInterface {
+	return &stockHandler{stockService: stockService}
+}
+
+func (h *stockHandler) GetAllStock(c *fiber.Ctx) error {
+	pageNumber, ok := c.Locals("pageNumber").(int)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve page number from context")
+		pageNumber = 1
+	}
+
+	pageSize, ok := c.Locals("pageSize").(int)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve page size from context")
+		pageSize = 100
+	}
+
+	nodeId, ok := c.Locals("nodeId").(int)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve nodeId from context")
+		nodeId = 0
+	}
+
+	stock, err := h.stockService.GetAllStock(c.UserContext(), pageNumber, pageSize, nodeId)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(stock)
+}
+
+func (h *stockHandler) CreateStock(c *fiber.Ctx) error {
+	body, ok := c.Locals("validatedBody").(dto.CreateStockRequest)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	stock, err := h.stockService.CreateStock(c.UserContext(), &body)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(stock)
+}
+
+func (h *stockHandler) UpdateStock(c *fiber.Ctx) error {
+	body, ok := c.Locals("validatedBody").(dto.UpdateStockRequest)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	stock, err := h.stockService.UpdateStock(c.UserContext(), &body)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(stock)
+}
+
+func (h *stockHandler) DeleteStock(c *fiber.Ctx) error {
+	stockIdStr, ok := c.Locals("Id").(string)
+	if !ok || stockIdStr == "0" {
+		log.FromContext(c.UserContext()).Error("ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "ID is required", nil).Send(c)
+	}
+
+	stockId, err := utils.StringToInt(stockIdStr)
+	if err != nil {
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, err.Error(), nil).Send(c)
+	}
+
+	err = h.stockService.DeleteStock(c.UserContext(), stockId)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"id": stockId})
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"shop/internal/api/dto"
+	"shop/internal/service"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+	"shop/pkg/utils"
+)
+
+type userHandler struct {
+	userService service.UserServiceInterface
+}
+
+type UserHandlerInterface interface {
+	UpdateUserRole(c *fiber.Ctx) error
+}
+
+func NewUserHandler(userService service.UserServiceInterface) UserHandlerInterface {
+	return &userHandler{userService: userService}
+}
+
+func (h *userHandler) UpdateUserRole(c *fiber.Ctx) error {
+	userIdStr, ok := c.Locals("Id").(string)
+	if !ok || userIdStr == "0" {
+		log.FromContext(c.UserContext()).Error("ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "ID is required", nil).Send(c)
+	}
+
+	userId, err := utils.StringToInt(userIdStr)
+	if err != nil {
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, err.Error(), nil).Send(c)
+	}
+
+	body, ok := c.Locals("validatedBody").(dto.UpdateUserRoleRequest)
+	if !ok {
+		log.FromContext(c.UserContext()).Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	user, err := h.userService.UpdateUserRole(c.UserContext(), userId, body.Role)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"shop/internal/model"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+)
+
+// RequirePermission пропускает запрос, только если роли пользователя выданы все перечисленные права.
+// Должен стоять после JwtAuthMiddleware.
+func RequirePermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, ok := GetUserRole(c)
+		if !ok {
+			return http_error.NewHTTPError(fiber.StatusUnauthorized, "Invalid token claims", nil).Send(c)
+		}
+
+		for _, permission := range permissions {
+			if !model.HasPermission(role, permission) {
+				log.FromContext(c.UserContext()).Warn("Permission denied",
+					zap.String("role", role),
+					zap.String("permission", permission))
+				return http_error.NewHTTPError(fiber.StatusForbidden, "Forbidden", nil).Send(c)
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireAnyPermission пропускает запрос, если роли пользователя выдано хотя бы одно из перечисленных прав.
+// Должен стоять после JwtAuthMiddleware.
+func RequireAnyPermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, ok := GetUserRole(c)
+		if !ok {
+			return http_error.NewHTTPError(fiber.StatusUnauthorized, "Invalid token claims", nil).Send(c)
+		}
+
+		for _, permission := range permissions {
+			if model.HasPermission(role, permission) {
+				return c.Next()
+			}
+		}
+
+		log.FromContext(c.UserContext()).Warn("Permission denied",
+			zap.String("role", role),
+			zap.Strings("permissions", permissions))
+		return http_error.NewHTTPError(fiber.StatusForbidden, "Forbidden", nil).Send(c)
+	}
+}
+
+// HasPermission сообщает, выдано ли право permission пользователю текущего запроса.
+func HasPermission(c *fiber.Ctx, permission string) bool {
+	role, ok := GetUserRole(c)
+	return ok && model.HasPermission(role, permission)
+}
@@ -2,8 +2,10 @@ package auth
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
 	"shop/internal/service"
 	"shop/pkg/http_error"
+	"shop/pkg/log"
 	"strings"
 )
 
@@ -36,8 +38,11 @@ func JwtAuthMiddleware(jwtService service.JWTServiceInterface) fiber.Handler {
 			return http_error.NewHTTPError(fiber.StatusUnauthorized, "Invalid token claims", nil).Send(c)
 		}
 
-		// Добавляем UserId в локальные данные запроса
+		// Добавляем UserId и роль в локальные данные запроса
 		c.Locals("userId", claims.UserId)
+		c.Locals("role", claims.Role)
+		// и в логгер запроса, чтобы записи сервисов и репозиториев содержали user_id
+		c.SetUserContext(log.With(c.UserContext(), zap.String("user_id", claims.UserId)))
 
 		// Продолжаем выполнение запроса
 		return c.Next()
@@ -49,3 +54,21 @@ func GetUserId(c *fiber.Ctx) (string, bool) {
 	userId, ok := c.Locals("userId").(string)
 	return userId, ok
 }
+
+// GetUserRole retrieves the user role from the Fiber context
+func GetUserRole(c *fiber.Ctx) (string, bool) {
+	role, ok := c.Locals("role").(string)
+	return role, ok
+}
+
+// OptionalJwtAuthMiddleware аутентифицирует запрос, только если передан заголовок Authorization.
+// Запросы без заголовка пропускаются анонимно, с некорректным токеном — отклоняются.
+func OptionalJwtAuthMiddleware(jwtService service.JWTServiceInterface) fiber.Handler {
+	required := JwtAuthMiddleware(jwtService)
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" {
+			return c.Next()
+		}
+		return required(c)
+	}
+}
@@ -1,24 +1,49 @@
 package middlewares
 
 import (
+	"errors"
 	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
+	"runtime/debug"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
 )
 
-// ErrorHandlerMiddleware handles global errors and logs them
-func ErrorHandlerMiddleware(logger *zap.Logger) func(c *fiber.Ctx) error {
-	return func(c *fiber.Ctx) error {
+// ErrorHandler — единый обработчик ошибок fiber (fiber.Config.ErrorHandler): любая ошибка, которую
+// вернули хендлер или middleware, отправляется клиенту как http_error.HTTPError (см. http_error.From).
+// Причина ошибок 5xx пишется в лог, но клиенту не передаётся; готовые HTTPError уже залогированы там, где их создали.
+// Пояснение PostgreSQL к нарушенному ограничению (ключи, значения) тоже попадает только в лог.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	httpErr := http_error.From(err)
+
+	var pqErr *pq.Error
+	switch {
+	case httpErr.StatusCode >= fiber.StatusInternalServerError && httpErr != err:
+		log.FromContext(c.UserContext()).Error("Request failed",
+			zap.Int("status_code", httpErr.StatusCode),
+			zap.Error(err))
+	case errors.As(err, &pqErr):
+		log.FromContext(c.UserContext()).Warn("Constraint violation",
+			zap.Int("status_code", httpErr.StatusCode),
+			zap.String("constraint", pqErr.Constraint),
+			zap.String("detail", pqErr.Detail))
+	}
+
+	return httpErr.Send(c)
+}
+
+// RecoverMiddleware перехватывает панику в хендлерах и middleware, стоящих после него: пишет в лог
+// стек вызовов вместе с X-Request-ID и отвечает 500 в формате http_error.HTTPError через ErrorHandler.
+func RecoverMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
 		defer func() {
 			if r := recover(); r != nil {
-				// Log the error with the provided logger
-				logger.Error("Recovered from panic",
-					zap.Any("error", r),
-					zap.String("path", c.Path()),
-					zap.String("method", c.Method()),
-				)
+				log.FromContext(c.UserContext()).Error("Recovered from panic",
+					zap.Any("panic", r),
+					zap.String("stack", string(debug.Stack())))
 
-				// Respond with a 500 status code
-				_ = c.Status(fiber.StatusInternalServerError).SendString("Internal Server Error")
+				err = http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil)
 			}
 		}()
 		return c.Next()
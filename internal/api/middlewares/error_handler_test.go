@@ -0,0 +1,132 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http/httptest"
+	"os"
+	"shop/pkg/app_error"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	log.InitLogger()
+	os.Exit(m.Run())
+}
+
+// errorResponse — тело ответа об ошибке (см. http_error.HTTPError.Send).
+type errorResponse struct {
+	Error   string                 `json:"error"`
+	Details []http_error.ErrorItem `json:"details"`
+}
+
+// doRequest отправляет GET path в приложение с ErrorHandler и возвращает статус и разобранное тело ответа.
+func doRequest(t *testing.T, app *fiber.App, path string) (int, errorResponse, string) {
+	t.Helper()
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+	require.NoError(t, err)
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var body errorResponse
+	require.NoError(t, json.Unmarshal(raw, &body), string(raw))
+	return resp.StatusCode, body, string(raw)
+}
+
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		message string
+	}{
+		{"not found", app_error.NotFound("Card not found"), fiber.StatusNotFound, "Card not found"},
+		{"wrapped conflict", fmt.Errorf("service: %w", app_error.Conflict("Node is not removed")), fiber.StatusConflict, "Node is not removed"},
+		{"validation", app_error.Validation("Unknown role"), fiber.StatusUnprocessableEntity, "Unknown role"},
+		{"forbidden", app_error.Forbidden("Access denied"), fiber.StatusForbidden, "Access denied"},
+		{"http error", http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid id", nil), fiber.StatusBadRequest, "Invalid id"},
+		{"fiber error", fiber.ErrMethodNotAllowed, fiber.StatusMethodNotAllowed, "Method Not Allowed"},
+		{"unauthorized", app_error.Unauthorized("Invalid email or password"), fiber.StatusUnauthorized, "Invalid email or password"},
+		{"postgres unique violation", &pq.Error{Code: "23505", Constraint: "size_title_key", Detail: "Key (title)=(XL) already exists."}, fiber.StatusConflict, "Size with this title already exists"},
+		{"timeout", fmt.Errorf("query: %w", context.DeadlineExceeded), fiber.StatusGatewayTimeout, "Request timed out"},
+		{"unexpected error", errors.New("pq: password authentication failed"), fiber.StatusInternalServerError, "Internal Server Error"},
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	for i, tt := range tests {
+		err := tt.err
+		app.Get(fmt.Sprintf("/%d", i), func(c *fiber.Ctx) error { return err })
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body, raw := doRequest(t, app, fmt.Sprintf("/%d", i))
+
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.message, body.Error)
+			assert.NotNil(t, body.Details)
+			assert.NotContains(t, raw, "pq:")
+			assert.NotContains(t, raw, "Key (")
+		})
+	}
+}
+
+func TestErrorHandlerUnknownRoute(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+
+	status, body, _ := doRequest(t, app, "/missing")
+	assert.Equal(t, fiber.StatusNotFound, status)
+	assert.Equal(t, "Cannot GET /missing", body.Error)
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	// Порядок middleware как в cmd/main.go
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(RecoverMiddleware())
+	app.Use(MetricsMiddleware())
+	app.Use(RequestLoggerMiddleware())
+	app.Get("/panic", func(c *fiber.Ctx) error {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/panic", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "req-1")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var body errorResponse
+	require.NoError(t, json.Unmarshal(raw, &body), string(raw))
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, "Internal Server Error", body.Error)
+	assert.Equal(t, "req-1", resp.Header.Get(fiber.HeaderXRequestID))
+	assert.NotContains(t, string(raw), "boom")
+}
+
+func TestRecoverMiddlewareBeforeOtherMiddleware(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(RecoverMiddleware())
+	app.Use(func(c *fiber.Ctx) error {
+		var labels map[string]string
+		labels["route"] = c.Path() // паника в middleware, а не в хендлере
+		return c.Next()
+	})
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	status, body, _ := doRequest(t, app, "/")
+	assert.Equal(t, fiber.StatusInternalServerError, status)
+	assert.Equal(t, "Internal Server Error", body.Error)
+}
@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"shop/pkg/metrics"
+	"strconv"
+	"time"
+)
+
+// MetricsMiddleware записывает длительность запроса в гистограмму metrics.HTTPRequestDuration
+// с метками method, route (шаблон маршрута) и status. Должен стоять перед RequestLoggerMiddleware:
+// тот отправляет ошибку через ErrorHandler, и здесь уже виден итоговый статус ответа.
+func MetricsMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		// Если ни один маршрут не подошёл, c.Route() — это глобальный middleware с путём "/"
+		route := c.Route().Path
+		if route == "/" && c.Path() != "/" {
+			route = "unmatched"
+		}
+
+		metrics.HTTPRequestDuration.
+			WithLabelValues(c.Method(), route, strconv.Itoa(c.Response().StatusCode())).
+			Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
@@ -0,0 +1,60 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"shop/configs/env"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+	"time"
+)
+
+// defaultRequestDeadline — время на обработку одного HTTP-запроса, если REQUEST_DEADLINE не задан.
+const defaultRequestDeadline = 5 * time.Second
+
+// RequestDeadlineMiddleware ограничивает время обработки запроса: контекст запроса (c.UserContext()),
+// который хендлеры передают в сервисы и репозитории, истекает через REQUEST_DEADLINE, и lib/pq
+// отменяет выполняющийся запрос к БД. Это дедлайн всего HTTP-запроса, а не statement_timeout PostgreSQL.
+// Если дедлайн истёк во время обработки, ошибка хендлера заменяется ошибкой 504.
+func RequestDeadlineMiddleware() fiber.Handler {
+	deadline := requestDeadline()
+
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), deadline)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		// Успешный ответ, успевший сформироваться к моменту дедлайна, не трогаем
+		if err == nil && c.Response().StatusCode() < fiber.StatusInternalServerError {
+			return nil
+		}
+		httpErr := http_error.FromContext(ctx)
+		if httpErr == nil {
+			return err
+		}
+
+		log.FromContext(c.UserContext()).Warn("Request deadline exceeded",
+			zap.Duration("deadline", deadline),
+			zap.Error(err))
+		return httpErr
+	}
+}
+
+// requestDeadline читает время на обработку запроса из REQUEST_DEADLINE (например, "5s").
+func requestDeadline() time.Duration {
+	raw := env.GetEnv("REQUEST_DEADLINE", "")
+	if raw == "" {
+		return defaultRequestDeadline
+	}
+
+	deadline, err := time.ParseDuration(raw)
+	if err != nil || deadline <= 0 {
+		log.Warn(fmt.Sprintf("Invalid REQUEST_DEADLINE, using default %s", defaultRequestDeadline), zap.String("value", raw))
+		return defaultRequestDeadline
+	}
+	return deadline
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"shop/pkg/app_error"
+	"testing"
+	"time"
+)
+
+func TestRequestDeadlineMiddleware(t *testing.T) {
+	t.Setenv("REQUEST_DEADLINE", "20ms")
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(RequestDeadlineMiddleware())
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		// Так lib/pq отвечает на запрос, отменённый по истечении контекста
+		<-c.UserContext().Done()
+		return &pq.Error{Code: "57014", Message: "canceling statement due to user request"}
+	})
+	app.Get("/missing", func(c *fiber.Ctx) error {
+		return app_error.NotFound("Card not found")
+	})
+	app.Get("/ok", func(c *fiber.Ctx) error {
+		deadline, ok := c.UserContext().Deadline()
+		assert.True(t, ok)
+		assert.LessOrEqual(t, time.Until(deadline), 20*time.Millisecond)
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	status, body, _ := doRequest(t, app, "/slow")
+	assert.Equal(t, fiber.StatusGatewayTimeout, status)
+	assert.Equal(t, "Request timed out", body.Error)
+
+	status, body, _ = doRequest(t, app, "/missing")
+	assert.Equal(t, fiber.StatusNotFound, status)
+	assert.Equal(t, "Card not found", body.Error)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/ok", nil))
+	if assert.NoError(t, err) {
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	}
+}
+
+func TestRequestDeadlineInvalidValue(t *testing.T) {
+	t.Setenv("REQUEST_DEADLINE", "-1s")
+	assert.Equal(t, defaultRequestDeadline, requestDeadline())
+
+	t.Setenv("REQUEST_DEADLINE", "1500ms")
+	assert.Equal(t, 1500*time.Millisecond, requestDeadline())
+}
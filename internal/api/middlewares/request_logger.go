@@ -9,28 +9,54 @@ import (
 	"go.uber.org/zap"
 )
 
-// RequestLoggerMiddleware logs request processing time along with request and response IDs
+// maxRequestIDLength ограничивает длину X-Request-ID, принятого от клиента или прокси.
+const maxRequestIDLength = 128
+
+// RequestLoggerMiddleware logs request processing time along with request and response IDs.
+// It also attaches a request-scoped logger to c.UserContext(), so log.FromContext in handlers,
+// services and repositories adds request_id, method and path to every entry.
 func RequestLoggerMiddleware() func(c *fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
-		// Generate a unique request ID
-		requestID := uuid.New().String()
-		c.Set("X-Request-ID", requestID)
+		// Honor the request ID set by the client or proxy, otherwise generate a unique one
+		requestID := c.Get(fiber.HeaderXRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+		c.Set(fiber.HeaderXRequestID, requestID)
+
+		c.SetUserContext(log.With(c.UserContext(),
+			zap.String("request_id", requestID),
+			zap.String("method", c.Method()),
+			zap.String("path", c.Path())))
 
 		start := time.Now()
 		err := c.Next()
+		// Ошибку отправляем клиенту здесь же, чтобы в лог попал итоговый статус ответа
+		if err != nil {
+			err = c.App().ErrorHandler(c, err)
+		}
 		duration := time.Since(start)
 
-		// Get the response status code
-		statusCode := c.Response().StatusCode()
-
-		// Log the request and response details
-		log.Info("Request processed",
-			zap.String("request_id", requestID),
-			zap.String("method", c.Method()),
-			zap.String("path", c.Path()),
-			zap.Int("status_code", statusCode),
+		// Log the request and response details; the logger also carries user_id if the request was authenticated
+		log.FromContext(c.UserContext()).Info("Request processed",
+			zap.String("route", c.Route().Path),
+			zap.Int("status_code", c.Response().StatusCode()),
 			zap.Duration("duration", duration))
 
 		return err
 	}
 }
+
+// validRequestID допускает только непустой ID разумной длины из видимых ASCII-символов,
+// чтобы значение из заголовка не ломало строки лога.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestValidRequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{"uuid", "3f2b8c1e-9d4a-4e6b-8f7a-1c2d3e4f5a6b", true},
+		{"short token", "abc", true},
+		{"printable punctuation", "req:42/retry#1~", true},
+		{"max length", strings.Repeat("a", maxRequestIDLength), true},
+		{"empty", "", false},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+		{"space", "abc def", false},
+		{"newline injects log lines", "abc\ninjected=1", false},
+		{"carriage return", "abc\r", false},
+		{"tab", "\tabc", false},
+		{"delete", "abc\x7f", false},
+		{"non-ascii", "запрос-1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, validRequestID(tt.id))
+		})
+	}
+}
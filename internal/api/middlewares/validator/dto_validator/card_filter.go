@@ -0,0 +1,212 @@
+package dto_validator
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"shop/internal/model"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// negatePrefix — префикс ключа, инвертирующий фильтр по характеристике: not:5=Красный.
+const negatePrefix = "not:"
+
+// rangeSeparator разделяет границы числового диапазона: 3=40..44, 7=..500.
+const rangeSeparator = ".."
+
+// maxSearchQueryLength — максимальная длина строки поиска q.
+const maxSearchQueryLength = 200
+
+// reservedCardQueryKeys — query-параметры списка карточек и фасетов, которые не являются характеристиками.
+var reservedCardQueryKeys = map[string]struct{}{
+	"pageNumber":     {},
+	"pageSize":       {},
+	"cursor":         {},
+	"includeRemoved": {},
+	"sort":           {},
+	"priceStep":      {},
+}
+
+// charFilterKey идентифицирует условие по характеристике: 5 и not:5 — разные условия.
+type charFilterKey struct {
+	charId int
+	negate bool
+}
+
+// ValidateCardFilterMiddleware разбирает фильтры списка карточек и сохраняет их в контекст как *model.CardFilter.
+//
+// Поддерживаемые параметры:
+//   - q=... — полнотекстовый поиск по названию и описанию (до maxSearchQueryLength символов);
+//   - searchConfig=russian|english|belarusian|simple — конфигурация поиска для q (по умолчанию — типа ноды);
+//   - nodeTypeId=1,2 — список типов нод;
+//   - currency=byn|rub — валюта для priceMin/priceMax и ценовой сортировки (по умолчанию byn);
+//   - priceMin / priceMax — границы цены;
+//   - sale=true|false — есть ли у товара скидка;
+//   - <id характеристики>=v1,v2 — любое из значений; значение вида a..b задаёт числовой диапазон;
+//   - <id характеристики>= — у товара есть характеристика;
+//   - not:<id характеристики>=... — отрицание любого из вариантов выше.
+//
+// Параметр можно повторять: 5=Красный&5=Синий равносильно 5=Красный,Синий.
+// Id характеристик возвращаются в фасетах (characteristicId).
+func ValidateCardFilterMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		filter := &model.CardFilter{Currency: model.CurrencyByn}
+		// Индекс условия по (характеристика, отрицание), чтобы объединять повторяющиеся ключи
+		charIndex := make(map[charFilterKey]int)
+
+		var details []http_error.ErrorItem
+
+		c.Context().QueryArgs().VisitAll(func(rawKey, rawValue []byte) {
+			key := strings.TrimSpace(string(rawKey))
+			value := strings.TrimSpace(string(rawValue))
+
+			if _, reserved := reservedCardQueryKeys[key]; reserved || key == "" {
+				return
+			}
+
+			switch key {
+			case "q":
+				if utf8.RuneCountInString(value) > maxSearchQueryLength {
+					details = append(details, http_error.ErrorItem{
+						Field: key,
+						Error: fmt.Sprintf("Must be at most %d characters", maxSearchQueryLength),
+					})
+					return
+				}
+				filter.Query = value
+			case "searchConfig":
+				if !model.IsValidSearchConfig(value) {
+					details = append(details, http_error.ErrorItem{
+						Field: key,
+						Error: "Must be one of: russian, english, belarusian, simple",
+					})
+					return
+				}
+				filter.SearchConfig = value
+			case "currency":
+				currency := strings.ToLower(value)
+				if currency != model.CurrencyByn && currency != model.CurrencyRub {
+					details = append(details, http_error.ErrorItem{Field: key, Error: "Must be byn or rub"})
+					return
+				}
+				filter.Currency = currency
+			case "nodeTypeId":
+				for _, part := range splitFilterValues(value) {
+					id, err := strconv.Atoi(part)
+					if err != nil || id < 1 {
+						details = append(details, http_error.ErrorItem{Field: key, Error: "Invalid node type id: " + part})
+						continue
+					}
+					filter.NodeTypeIds = append(filter.NodeTypeIds, id)
+				}
+			case "priceMin", "priceMax":
+				price, err := strconv.Atoi(value)
+				if err != nil || price < 0 {
+					details = append(details, http_error.ErrorItem{Field: key, Error: "Must be a non-negative integer"})
+					return
+				}
+				if key == "priceMin" {
+					filter.PriceMin = &price
+				} else {
+					filter.PriceMax = &price
+				}
+			case "sale":
+				sale, err := strconv.ParseBool(value)
+				if err != nil {
+					details = append(details, http_error.ErrorItem{Field: key, Error: "Must be true or false"})
+					return
+				}
+				filter.Sale = &sale
+			default:
+				negate := strings.HasPrefix(key, negatePrefix)
+				charId, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(key, negatePrefix)))
+				if err != nil || charId < 1 {
+					details = append(details, http_error.ErrorItem{Field: key, Error: "Unknown parameter or invalid characteristic id"})
+					return
+				}
+
+				indexKey := charFilterKey{charId: charId, negate: negate}
+				idx, exists := charIndex[indexKey]
+				if !exists {
+					filter.Characteristics = append(filter.Characteristics, model.CharacteristicFilter{
+						CharacteristicId: charId,
+						Negate:           negate,
+					})
+					idx = len(filter.Characteristics) - 1
+					charIndex[indexKey] = idx
+				}
+				charFilter := &filter.Characteristics[idx]
+
+				for _, part := range splitFilterValues(value) {
+					if !strings.Contains(part, rangeSeparator) {
+						charFilter.Values = append(charFilter.Values, part)
+						continue
+					}
+					valueRange, ok := parseValueRange(part)
+					if !ok {
+						details = append(details, http_error.ErrorItem{Field: key, Error: "Invalid range: " + part})
+						continue
+					}
+					charFilter.Ranges = append(charFilter.Ranges, valueRange)
+				}
+			}
+		})
+
+		if filter.PriceMin != nil && filter.PriceMax != nil && *filter.PriceMin > *filter.PriceMax {
+			details = append(details, http_error.ErrorItem{Field: "priceMin", Error: "Must not be greater than priceMax"})
+		}
+
+		if len(details) > 0 {
+			log.FromContext(c.UserContext()).Error("Invalid card filter", zap.Any("details", details))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid filter", details).Send(c)
+		}
+
+		c.Locals("cardFilter", filter)
+
+		return c.Next()
+	}
+}
+
+// splitFilterValues разбивает значение параметра по запятым, отбрасывая пустые элементы.
+func splitFilterValues(value string) []string {
+	var parts []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// parseValueRange разбирает диапазон вида a..b; любая из границ может быть опущена, но не обе сразу.
+func parseValueRange(value string) (model.ValueRange, bool) {
+	minPart, maxPart, _ := strings.Cut(value, rangeSeparator)
+	minPart, maxPart = strings.TrimSpace(minPart), strings.TrimSpace(maxPart)
+	if minPart == "" && maxPart == "" {
+		return model.ValueRange{}, false
+	}
+
+	var result model.ValueRange
+	if minPart != "" {
+		minValue, err := strconv.ParseFloat(minPart, 64)
+		if err != nil {
+			return model.ValueRange{}, false
+		}
+		result.Min = &minValue
+	}
+	if maxPart != "" {
+		maxValue, err := strconv.ParseFloat(maxPart, 64)
+		if err != nil {
+			return model.ValueRange{}, false
+		}
+		result.Max = &maxValue
+	}
+	if result.Min != nil && result.Max != nil && *result.Min > *result.Max {
+		return model.ValueRange{}, false
+	}
+	return result, true
+}
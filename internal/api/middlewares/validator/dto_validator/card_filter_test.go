@@ -0,0 +1,173 @@
+package dto_validator
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"os"
+	"shop/internal/model"
+	"shop/pkg/log"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	log.InitLogger()
+	os.Exit(m.Run())
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+func intPtr(v int) *int { return &v }
+
+func boolPtr(v bool) *bool { return &v }
+
+func TestParseValueRange(t *testing.T) {
+	tests := []struct {
+		value string
+		want  model.ValueRange
+		ok    bool
+	}{
+		{"40..44", model.ValueRange{Min: floatPtr(40), Max: floatPtr(44)}, true},
+		{"40..", model.ValueRange{Min: floatPtr(40)}, true},
+		{"..500", model.ValueRange{Max: floatPtr(500)}, true},
+		{" 1.5 .. 2.5 ", model.ValueRange{Min: floatPtr(1.5), Max: floatPtr(2.5)}, true},
+		{"-10..-5", model.ValueRange{Min: floatPtr(-10), Max: floatPtr(-5)}, true},
+		{"42..42", model.ValueRange{Min: floatPtr(42), Max: floatPtr(42)}, true},
+		{"..", model.ValueRange{}, false},
+		{"44..40", model.ValueRange{}, false},
+		{"a..b", model.ValueRange{}, false},
+		{"1..x", model.ValueRange{}, false},
+		{"1..2..3", model.ValueRange{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseValueRange(tt.value)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// runCardFilter прогоняет query через ValidateCardFilterMiddleware и возвращает статус ответа
+// и разобранный фильтр (nil, если middleware ответил ошибкой).
+func runCardFilter(t *testing.T, query string) (int, *model.CardFilter) {
+	t.Helper()
+
+	var filter *model.CardFilter
+	app := fiber.New()
+	app.Get("/cards", ValidateCardFilterMiddleware(), func(c *fiber.Ctx) error {
+		filter, _ = c.Locals("cardFilter").(*model.CardFilter)
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/cards?"+query, nil))
+	require.NoError(t, err)
+	return resp.StatusCode, filter
+}
+
+func TestValidateCardFilterMiddleware(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  *model.CardFilter
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  &model.CardFilter{Currency: model.CurrencyByn},
+		},
+		{
+			name:  "reserved keys are skipped",
+			query: "pageNumber=2&pageSize=10&cursor=abc&sort=title&includeRemoved=true&priceStep=50",
+			want:  &model.CardFilter{Currency: model.CurrencyByn},
+		},
+		{
+			name:  "search query",
+			query: "q=%20летнее%20платье%20&searchConfig=russian",
+			want:  &model.CardFilter{Currency: model.CurrencyByn, Query: "летнее платье", SearchConfig: "russian"},
+		},
+		{
+			name:  "values of one characteristic",
+			query: "3=M,L&3=XL",
+			want: &model.CardFilter{Currency: model.CurrencyByn, Characteristics: []model.CharacteristicFilter{
+				{CharacteristicId: 3, Values: []string{"M", "L", "XL"}},
+			}},
+		},
+		{
+			name:  "range and value",
+			query: "7=40..44,S",
+			want: &model.CardFilter{Currency: model.CurrencyByn, Characteristics: []model.CharacteristicFilter{
+				{CharacteristicId: 7, Values: []string{"S"}, Ranges: []model.ValueRange{{Min: floatPtr(40), Max: floatPtr(44)}}},
+			}},
+		},
+		{
+			name:  "negation is a separate condition",
+			query: "5=Red&not:5=Blue",
+			want: &model.CardFilter{Currency: model.CurrencyByn, Characteristics: []model.CharacteristicFilter{
+				{CharacteristicId: 5, Values: []string{"Red"}},
+				{CharacteristicId: 5, Values: []string{"Blue"}, Negate: true},
+			}},
+		},
+		{
+			name:  "presence of a characteristic",
+			query: "not:9=",
+			want: &model.CardFilter{Currency: model.CurrencyByn, Characteristics: []model.CharacteristicFilter{
+				{CharacteristicId: 9, Negate: true},
+			}},
+		},
+		{
+			name:  "price, currency, sale and node types",
+			query: "priceMin=10&priceMax=20&currency=RUB&sale=true&nodeTypeId=1,2",
+			want: &model.CardFilter{
+				Currency:    model.CurrencyRub,
+				PriceMin:    intPtr(10),
+				PriceMax:    intPtr(20),
+				Sale:        boolPtr(true),
+				NodeTypeIds: []int{1, 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, filter := runCardFilter(t, tt.query)
+			require.Equal(t, fiber.StatusOK, status)
+			assert.Equal(t, tt.want, filter)
+		})
+	}
+}
+
+func TestValidateCardFilterMiddlewareRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"search query too long", "q=" + strings.Repeat("a", maxSearchQueryLength+1)},
+		{"characteristic title instead of id", "Color=Red"},
+		{"size is not reserved", "size=M"},
+		{"page is not reserved", "page=2"},
+		{"zero characteristic id", "0=Red"},
+		{"negative characteristic id", "not:-1=Red"},
+		{"empty negation", "not:=Red"},
+		{"bad range", "3=44..40"},
+		{"range without bounds", "3=.."},
+		{"non-numeric range", "3=a..b"},
+		{"negative price", "priceMin=-1"},
+		{"price bounds swapped", "priceMin=20&priceMax=10"},
+		{"unknown currency", "currency=usd"},
+		{"bad sale", "sale=maybe"},
+		{"bad node type", "nodeTypeId=1,x"},
+		{"unknown search config", "searchConfig=german"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, filter := runCardFilter(t, tt.query)
+			assert.Equal(t, fiber.StatusBadRequest, status)
+			assert.Nil(t, filter)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package dto_validator
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"shop/internal/model"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+	"strings"
+)
+
+// ValidateCardSortMiddleware проверяет query-параметр sort по белому списку сортировок
+// и сохраняет его в контекст. По умолчанию используется relevance при поиске (q) и newest без него.
+func ValidateCardSortMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		defaultSort := model.CardSortNewest
+		if strings.TrimSpace(c.Query("q")) != "" {
+			defaultSort = model.CardSortRelevance
+		}
+
+		sort := c.Query("sort", defaultSort)
+		if !model.IsValidCardSort(sort) {
+			log.FromContext(c.UserContext()).Error("Invalid sort parameter", zap.String("sort", sort))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid sort parameter", []http_error.ErrorItem{{
+				Field: "sort",
+				Error: "Must be one of: newest, price_asc, price_desc, title, discount, relevance",
+			}}).Send(c)
+		}
+
+		c.Locals("cardSort", sort)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package dto_validator
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"shop/internal/model"
+	"testing"
+)
+
+func TestValidateCardSortMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		sort   string
+	}{
+		{"default without search", "", fiber.StatusOK, model.CardSortNewest},
+		{"default with search", "q=dress", fiber.StatusOK, model.CardSortRelevance},
+		{"blank search keeps newest", "q=%20", fiber.StatusOK, model.CardSortNewest},
+		{"explicit sort with search", "q=dress&sort=price_asc", fiber.StatusOK, model.CardSortPriceAsc},
+		{"price desc", "sort=price_desc", fiber.StatusOK, model.CardSortPriceDesc},
+		{"title", "sort=title", fiber.StatusOK, model.CardSortTitle},
+		{"discount", "sort=discount", fiber.StatusOK, model.CardSortDiscount},
+		{"relevance without search", "sort=relevance", fiber.StatusOK, model.CardSortRelevance},
+		{"unknown sort", "sort=created_at", fiber.StatusBadRequest, ""},
+		{"sql injection", "sort=title;DROP%20TABLE", fiber.StatusBadRequest, ""},
+		{"case sensitive", "sort=TITLE", fiber.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sort string
+			app := fiber.New()
+			app.Get("/cards", ValidateCardSortMiddleware(), func(c *fiber.Ctx) error {
+				sort, _ = c.Locals("cardSort").(string)
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/cards?"+tt.query, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.sort, sort)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package dto_validator
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"shop/pkg/app_error"
+	"shop/pkg/http_error"
+)
+
+// sendCheckIdsError отвечает на ошибку проверки существования id (CheckNodesByIds, CheckCharsByIds).
+// Ошибка валидации отдаётся как 400 с пояснением для поля field; прочие ошибки (например, сбой БД)
+// возвращаются как есть, и ErrorHandler не передаёт их причину клиенту.
+func sendCheckIdsError(c *fiber.Ctx, err error, field string) error {
+	var appErr *app_error.Error
+	if !errors.As(err, &appErr) || appErr.Kind != app_error.ErrValidation {
+		return err
+	}
+
+	return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid input", []http_error.ErrorItem{{
+		Field: field,
+		Error: appErr.Message,
+	}}).Send(c)
+}
@@ -0,0 +1,61 @@
+package dto_validator
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http/httptest"
+	"shop/pkg/app_error"
+	"testing"
+)
+
+func TestSendCheckIdsError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		body   string
+	}{
+		{
+			name:   "missing ids",
+			err:    fmt.Errorf("repo: %w", app_error.Validation("these node IDs don't exist: [7]")),
+			status: fiber.StatusBadRequest,
+			body:   `{"error":"Invalid input","details":[{"field":"nodeid","error":"these node IDs don't exist: [7]"}]}`,
+		},
+		{
+			name:   "database error is passed on",
+			err:    errors.New("pq: connection refused"),
+			status: fiber.StatusTeapot,
+			body:   `{"error":"handled"}`,
+		},
+		{
+			name:   "other domain error is passed on",
+			err:    app_error.NotFound("Node not found"),
+			status: fiber.StatusTeapot,
+			body:   `{"error":"handled"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: func(c *fiber.Ctx, err error) error {
+				assert.Same(t, tt.err, err)
+				return c.Status(fiber.StatusTeapot).JSON(fiber.Map{"error": "handled"})
+			}})
+			app.Get("/", func(c *fiber.Ctx) error {
+				return sendCheckIdsError(c, tt.err, "nodeId")
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.JSONEq(t, tt.body, string(body))
+		})
+	}
+}
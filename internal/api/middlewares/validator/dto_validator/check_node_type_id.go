@@ -11,7 +11,7 @@ import (
 
 // ValidateNodeTypeIdMiddleware проверяет корректность и существование nodeTypeId
 // в query-параметре и при необходимости прерывает выполнение с соответствующей ошибкой.
-func ValidateNodeTypeIdMiddleware() fiber.Handler {
+func ValidateNodeTypeIdMiddleware(nodeTypeRepo repository.NodeTypeRepositoryInterface) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 
 		if !c.Context().QueryArgs().Has("nodeTypeId") {
@@ -28,14 +28,14 @@ func ValidateNodeTypeIdMiddleware() fiber.Handler {
 		nodeTypeID, err := strconv.Atoi(nodeTypeParam)
 		if err != nil {
 			// Если преобразование не удалось — это ошибка клиента
-			log.Error("Invalid nodeTypeId parameter", zap.String("nodeTypeId", nodeTypeParam), zap.Error(err))
+			log.FromContext(c.UserContext()).Error("Invalid nodeTypeId parameter", zap.String("nodeTypeId", nodeTypeParam), zap.Error(err))
 			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid nodeTypeId parameter", nil).Send(c)
 		}
 
-		nodeType, err := repository.NodeTypeRepo.GetNodeTypeById(nodeTypeID)
+		nodeType, err := nodeTypeRepo.GetNodeTypeById(c.UserContext(), nodeTypeID)
 		if err != nil {
 			// Если не найдено — возвращаем 404
-			log.Error("nodeTypeId not found in repository",
+			log.FromContext(c.UserContext()).Error("nodeTypeId not found in repository",
 				zap.Int("nodeTypeId", nodeTypeID),
 				zap.Error(err))
 			return http_error.NewHTTPError(fiber.StatusNotFound, "NodeType not found", nil).Send(c)
@@ -11,12 +11,12 @@ import (
 	"shop/pkg/log"
 )
 
-func ValidateCreateCardMiddleware() fiber.Handler {
+func ValidateCreateCardMiddleware(characteristicRepo repository.CharacteristicRepositoryInterface) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Parse the input data.
 		var req dto.CreateCardDTO
 		if err := c.BodyParser(&req); err != nil {
-			log.Error("Failed to parse request body", zap.Error(err))
+			log.FromContext(c.UserContext()).Error("Failed to parse request body", zap.Error(err))
 			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid request body", nil).Send(c)
 		}
 
@@ -27,7 +27,7 @@ func ValidateCreateCardMiddleware() fiber.Handler {
 
 		// validate the input data.
 		if err := validate.Struct(&req); err != nil {
-			log.Error("Validation failed for request body", zap.Error(err))
+			log.FromContext(c.UserContext()).Error("Validation failed for request body", zap.Error(err))
 
 			// If the error is a validation error.
 			if validationErrors, ok := err.(validator.ValidationErrors); ok {
@@ -39,12 +39,9 @@ func ValidateCreateCardMiddleware() fiber.Handler {
 			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid input", nil).Send(c)
 		}
 
-		err := repository.CharacteristicRepo.CheckCharsByIds(ids)
+		err := characteristicRepo.CheckCharsByIds(c.UserContext(), ids)
 		if err != nil {
-			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid input", []http_error.ErrorItem{{
-				Field: "id",
-				Error: err.Error(),
-			}}).Send(c)
+			return sendCheckIdsError(c, err, "id")
 		}
 		// Store the validated data in context for use in the handler.
 		c.Locals("validatedBody", req)
@@ -15,13 +15,13 @@ func ValidateCreateNodeTypeMiddleware() fiber.Handler {
 		// Parse the input data.
 		var req dto.CreateNodeTypeRequest
 		if err := c.BodyParser(&req); err != nil {
-			log.Error("Failed to parse request body", zap.Error(err))
+			log.FromContext(c.UserContext()).Error("Failed to parse request body", zap.Error(err))
 			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid request body", nil).Send(c)
 		}
 
 		// validate the input data.
 		if err := validate.Struct(&req); err != nil {
-			log.Error("Validation failed for request body", zap.Error(err))
+			log.FromContext(c.UserContext()).Error("Validation failed for request body", zap.Error(err))
 
 			// If the error is a validation error.
 			if validationErrors, ok := err.(validator.ValidationErrors); ok {
@@ -3,6 +3,7 @@ package dto_validator
 import (
 	"shop/internal/api/dto"
 	"shop/internal/api/middlewares/validator/format_validation_error"
+	"shop/internal/repository"
 	"shop/pkg/http_error"
 	"shop/pkg/log"
 
@@ -11,11 +12,11 @@ import (
 	"go.uber.org/zap"
 )
 
-func ValidateCreateOrderMiddleware() fiber.Handler {
+func ValidateCreateOrderMiddleware(nodeRepo repository.NodeRepositoryInterface) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		var req []dto.OrderDTO
 		if err := c.BodyParser(&req); err != nil {
-			log.Error("Failed to parse request body", zap.Error(err))
+			log.FromContext(c.UserContext()).Error("Failed to parse request body", zap.Error(err))
 			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid request body", nil).Send(c)
 		}
 
@@ -27,7 +28,7 @@ func ValidateCreateOrderMiddleware() fiber.Handler {
 		// validate each item in the array
 		for i, order := range req {
 			if err := validate.Struct(&order); err != nil {
-				log.Error("Validation failed for request body", zap.Error(err), zap.Int("orderIndex", i))
+				log.FromContext(c.UserContext()).Error("Validation failed for request body", zap.Error(err), zap.Int("orderIndex", i))
 
 				// If the error is a validation error.
 				if validationErrors, ok := err.(validator.ValidationErrors); ok {
@@ -40,6 +41,15 @@ func ValidateCreateOrderMiddleware() fiber.Handler {
 			}
 		}
 
+		ids := make([]int, 0, len(req))
+		for _, order := range req {
+			ids = append(ids, order.NodeId)
+		}
+
+		if err := nodeRepo.CheckNodesByIds(c.UserContext(), ids); err != nil {
+			return sendCheckIdsError(c, err, "nodeId")
+		}
+
 		// Store the validated data in context for use in the handler.
 		c.Locals("validatedBody", req)
 
@@ -10,7 +10,7 @@ func ValidateIdMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		userId := c.Params("id")
 		if userId == "" {
-			log.Error("Id param is missing in the request")
+			log.FromContext(c.UserContext()).Error("Id param is missing in the request")
 			return http_error.NewHTTPError(fiber.StatusBadRequest, "Id param is required", nil).Send(c)
 		}
 
@@ -0,0 +1,38 @@
+package dto_validator
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"shop/internal/api/middlewares/auth"
+	"shop/internal/model"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+	"strconv"
+)
+
+// ValidateIncludeRemovedMiddleware разбирает query-параметр includeRemoved и сохраняет его в контекст.
+// Удалённые ноды видны только пользователям с правом cards:read:removed, поэтому перед этим
+// middleware должен стоять OptionalJwtAuthMiddleware.
+func ValidateIncludeRemovedMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		raw := c.Query("includeRemoved", "false")
+		includeRemoved, err := strconv.ParseBool(raw)
+		if err != nil {
+			log.FromContext(c.UserContext()).Error("Invalid includeRemoved parameter", zap.String("includeRemoved", raw))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid includeRemoved parameter", nil).Send(c)
+		}
+
+		if includeRemoved {
+			if _, ok := auth.GetUserRole(c); !ok {
+				return http_error.NewHTTPError(fiber.StatusUnauthorized, "Authorization header is missing", nil).Send(c)
+			}
+			if !auth.HasPermission(c, model.PermCardsReadRemoved) {
+				return http_error.NewHTTPError(fiber.StatusForbidden, "Forbidden", nil).Send(c)
+			}
+		}
+
+		c.Locals("includeRemoved", includeRemoved)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package dto_validator
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+	"strconv"
+)
+
+// ValidateNodeIdQueryMiddleware проверяет необязательный query-параметр nodeId.
+// Если параметр не передан, в контекст сохраняется 0.
+func ValidateNodeIdQueryMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !c.Context().QueryArgs().Has("nodeId") {
+			c.Locals("nodeId", 0)
+			return c.Next()
+		}
+
+		nodeIdParam := c.Query("nodeId", "")
+		nodeId, err := strconv.Atoi(nodeIdParam)
+		if err != nil || nodeId < 1 {
+			log.FromContext(c.UserContext()).Error("Invalid nodeId parameter", zap.String("nodeId", nodeIdParam))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid nodeId parameter", nil).Send(c)
+		}
+
+		c.Locals("nodeId", nodeId)
+
+		return c.Next()
+	}
+}
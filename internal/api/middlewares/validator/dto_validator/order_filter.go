@@ -0,0 +1,74 @@
+package dto_validator
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"shop/internal/model"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+	"strings"
+	"time"
+)
+
+// ValidateOrderFilterMiddleware разбирает query-параметры status, dateFrom и dateTo
+// и сохраняет их в контекст как *model.OrderFilter.
+//
+// status — список статусов через запятую, dateFrom/dateTo — дата в формате 2006-01-02 или RFC3339.
+// dateTo в формате даты включает весь указанный день.
+func ValidateOrderFilterMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		filter := &model.OrderFilter{}
+
+		if statusParam := c.Query("status", ""); statusParam != "" {
+			for _, status := range strings.Split(statusParam, ",") {
+				status = strings.TrimSpace(status)
+				if status == "" {
+					continue
+				}
+				if !model.IsValidOrderStatus(status) {
+					log.FromContext(c.UserContext()).Error("Invalid order status filter", zap.String("status", status))
+					return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid status parameter", []http_error.ErrorItem{{
+						Field: "status",
+						Error: "Unknown order status: " + status,
+					}}).Send(c)
+				}
+				filter.Statuses = append(filter.Statuses, status)
+			}
+		}
+
+		if dateFromParam := c.Query("dateFrom", ""); dateFromParam != "" {
+			dateFrom, _, err := parseFilterDate(dateFromParam)
+			if err != nil {
+				log.FromContext(c.UserContext()).Error("Invalid dateFrom parameter", zap.String("dateFrom", dateFromParam), zap.Error(err))
+				return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid dateFrom parameter", nil).Send(c)
+			}
+			filter.DateFrom = &dateFrom
+		}
+
+		if dateToParam := c.Query("dateTo", ""); dateToParam != "" {
+			dateTo, isDateOnly, err := parseFilterDate(dateToParam)
+			if err != nil {
+				log.FromContext(c.UserContext()).Error("Invalid dateTo parameter", zap.String("dateTo", dateToParam), zap.Error(err))
+				return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid dateTo parameter", nil).Send(c)
+			}
+			if isDateOnly {
+				dateTo = dateTo.AddDate(0, 0, 1)
+			}
+			filter.DateTo = &dateTo
+		}
+
+		c.Locals("orderFilter", filter)
+
+		return c.Next()
+	}
+}
+
+// parseFilterDate разбирает дату в формате 2006-01-02 или RFC3339.
+// Второе значение сообщает, была ли передана только дата без времени.
+func parseFilterDate(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
@@ -14,7 +14,7 @@ func ValidatePaginationMiddleware() fiber.Handler {
 		pageStr := c.Query("page", "1")
 		pageNumber, err := strconv.Atoi(pageStr)
 		if err != nil || pageNumber < 1 {
-			log.Error("Invalid page number", zap.String("page", pageStr))
+			log.FromContext(c.UserContext()).Error("Invalid page number", zap.String("page", pageStr))
 			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid page number", nil).Send(c)
 		}
 
@@ -22,7 +22,7 @@ func ValidatePaginationMiddleware() fiber.Handler {
 		sizeStr := c.Query("size", "10")
 		pageSize, err := strconv.Atoi(sizeStr)
 		if err != nil || pageSize < 1 {
-			log.Error("Invalid page size", zap.String("size", sizeStr))
+			log.FromContext(c.UserContext()).Error("Invalid page size", zap.String("size", sizeStr))
 			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid page size", nil).Send(c)
 		}
 
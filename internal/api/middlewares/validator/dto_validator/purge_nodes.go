@@ -0,0 +1,32 @@
+package dto_validator
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+	"strconv"
+)
+
+// ValidatePurgeNodesMiddleware проверяет необязательный query-параметр olderThanDays — сколько дней
+// (не меньше одного) нода должна пролежать удалённой, чтобы её можно было удалить физически.
+// Ноль не допускается: иначе только что удалённые ноды стирались бы без возможности восстановления.
+// Если параметр не передан, в контекст ничего не сохраняется и используется срок по умолчанию.
+func ValidatePurgeNodesMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !c.Context().QueryArgs().Has("olderThanDays") {
+			return c.Next()
+		}
+
+		daysParam := c.Query("olderThanDays", "")
+		days, err := strconv.Atoi(daysParam)
+		if err != nil || days < 1 {
+			log.FromContext(c.UserContext()).Error("Invalid olderThanDays parameter", zap.String("olderThanDays", daysParam))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid olderThanDays parameter", nil).Send(c)
+		}
+
+		c.Locals("olderThanDays", days)
+
+		return c.Next()
+	}
+}
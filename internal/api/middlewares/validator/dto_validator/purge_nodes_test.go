@@ -0,0 +1,42 @@
+package dto_validator
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidatePurgeNodesMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		days   interface{}
+	}{
+		{"default", "", fiber.StatusOK, nil},
+		{"one day", "olderThanDays=1", fiber.StatusOK, 1},
+		{"month", "olderThanDays=30", fiber.StatusOK, 30},
+		{"zero purges just removed nodes", "olderThanDays=0", fiber.StatusBadRequest, nil},
+		{"negative", "olderThanDays=-5", fiber.StatusBadRequest, nil},
+		{"empty", "olderThanDays=", fiber.StatusBadRequest, nil},
+		{"not a number", "olderThanDays=week", fiber.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var days interface{}
+			app := fiber.New()
+			app.Delete("/nodes/purge", ValidatePurgeNodesMiddleware(), func(c *fiber.Ctx) error {
+				days = c.Locals("olderThanDays")
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodDelete, "/nodes/purge?"+tt.query, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.days, days)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package dto_validator
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"shop/internal/model"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidateSuggestMiddleware проверяет query-параметры подсказок поиска: обязательную строку q
+// (до model.MaxSuggestQueryLength символов) и необязательный limit от 1 до model.MaxSuggestLimit.
+// Сохраняет в контекст "suggestQuery" и "suggestLimit".
+func ValidateSuggestMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var details []http_error.ErrorItem
+
+		query := strings.TrimSpace(c.Query("q"))
+		if query == "" {
+			details = append(details, http_error.ErrorItem{Field: "q", Error: "Is required"})
+		} else if utf8.RuneCountInString(query) > model.MaxSuggestQueryLength {
+			details = append(details, http_error.ErrorItem{
+				Field: "q",
+				Error: fmt.Sprintf("Must be at most %d characters", model.MaxSuggestQueryLength),
+			})
+		}
+
+		limit := model.DefaultSuggestLimit
+		if rawLimit := c.Query("limit"); rawLimit != "" {
+			var err error
+			limit, err = strconv.Atoi(rawLimit)
+			if err != nil || limit < 1 || limit > model.MaxSuggestLimit {
+				details = append(details, http_error.ErrorItem{
+					Field: "limit",
+					Error: fmt.Sprintf("Must be a number from 1 to %d", model.MaxSuggestLimit),
+				})
+			}
+		}
+
+		if len(details) > 0 {
+			log.FromContext(c.UserContext()).Error("Invalid suggest parameters", zap.Any("details", details))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid suggest parameters", details).Send(c)
+		}
+
+		c.Locals("suggestQuery", query)
+		c.Locals("suggestLimit", limit)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,30 @@
+package dto_validator
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+)
+
+// ValidateUUIDMiddleware проверяет, что параметр :id является корректным UUID.
+func ValidateUUIDMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		if id == "" {
+			log.FromContext(c.UserContext()).Error("Id param is missing in the request")
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Id param is required", nil).Send(c)
+		}
+
+		if _, err := uuid.Parse(id); err != nil {
+			log.FromContext(c.UserContext()).Error("Invalid UUID in id param", zap.String("id", id), zap.Error(err))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid id param", nil).Send(c)
+		}
+
+		// Store the ID in context for use in the handler.
+		c.Locals("Id", id)
+
+		return c.Next()
+	}
+}
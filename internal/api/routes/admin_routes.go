@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"shop/internal/api/middlewares/auth"
+	"shop/internal/api/middlewares/validator/dto_validator"
+	"shop/internal/container"
+	"shop/internal/model"
+)
+
+func RegisterAdminRoutes(app fiber.Router, deps *container.Container) {
+	jwtAuth := auth.JwtAuthMiddleware(deps.Services.JWT)
+	canManageLogs := auth.RequirePermission(model.PermLogsWrite)
+
+	app.Get("/admin/log-level",
+		jwtAuth,
+		canManageLogs,
+		deps.Handlers.Log.GetLogLevel,
+	)
+
+	app.Put("/admin/log-level",
+		jwtAuth,
+		canManageLogs,
+		dto_validator.ValidateUpdateLogLevelMiddleware(),
+		deps.Handlers.Log.UpdateLogLevel,
+	)
+}
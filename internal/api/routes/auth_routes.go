@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"shop/internal/api/middlewares/auth"
+	"shop/internal/api/middlewares/validator/dto_validator"
+	"shop/internal/container"
+)
+
+func RegisterAuthRoutes(app fiber.Router, deps *container.Container) {
+	jwtAuth := auth.JwtAuthMiddleware(deps.Services.JWT)
+
+	app.Post("/auth/register",
+		dto_validator.ValidateCreateUserMiddleware(),
+		deps.Handlers.Auth.Register,
+	)
+
+	app.Post("/auth/login",
+		dto_validator.ValidateLoginMiddleware(),
+		deps.Handlers.Auth.Login,
+	)
+
+	app.Post("/auth/refresh",
+		dto_validator.ValidateRefreshTokenMiddleware(),
+		deps.Handlers.Auth.Refresh,
+	)
+
+	app.Post("/auth/logout",
+		dto_validator.ValidateRefreshTokenMiddleware(),
+		deps.Handlers.Auth.Logout,
+	)
+
+	app.Get("/auth/me",
+		jwtAuth,
+		deps.Handlers.Auth.Me,
+	)
+}
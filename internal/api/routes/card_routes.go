@@ -2,26 +2,66 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"shop/internal/api/handlers"
+	"shop/internal/api/middlewares/auth"
 	"shop/internal/api/middlewares/validator/dto_validator"
+	"shop/internal/container"
+	"shop/internal/model"
 )
 
-func RegisterCardRoutes(app fiber.Router) {
+func RegisterCardRoutes(app fiber.Router, deps *container.Container) {
+	jwtAuth := auth.JwtAuthMiddleware(deps.Services.JWT)
+	optionalAuth := auth.OptionalJwtAuthMiddleware(deps.Services.JWT)
+	canWrite := auth.RequirePermission(model.PermCardsWrite)
+
+	// /cards/facets и /cards/suggest регистрируются до /cards/:id, иначе они будут разобраны как id
+	app.Get("/cards/suggest",
+		dto_validator.ValidateSuggestMiddleware(),
+		deps.Handlers.Card.Suggest,
+	)
+	app.Get("/cards/facets",
+		optionalAuth,
+		dto_validator.ValidateIncludeRemovedMiddleware(),
+		dto_validator.ValidateCardFilterMiddleware(),
+		deps.Handlers.Card.GetCardFacets,
+	)
 	app.Get("/cards/:id",
+		optionalAuth,
 		dto_validator.ValidateIdMiddleware(),
-		handlers.CardHandler.GetCardById,
+		dto_validator.ValidateIncludeRemovedMiddleware(),
+		deps.Handlers.Card.GetCardById,
 	)
 	app.Get("/cards",
-		dto_validator.ValidateNodeTypeIdMiddleware(),
+		optionalAuth,
+		dto_validator.ValidateIncludeRemovedMiddleware(),
+		dto_validator.ValidateCardFilterMiddleware(),
+		dto_validator.ValidateCardSortMiddleware(),
 		dto_validator.ValidatePaginationMiddleware(),
-		handlers.CardHandler.GetAllCards,
+		deps.Handlers.Card.GetAllCards,
 	)
 	app.Post("/cards",
-		dto_validator.ValidateCreateCardMiddleware(),
-		handlers.CardHandler.CreateCard,
+		jwtAuth,
+		canWrite,
+		dto_validator.ValidateCreateCardMiddleware(deps.Repos.Characteristic),
+		deps.Handlers.Card.CreateCard,
+	)
+	app.Put("/cards/:id",
+		jwtAuth,
+		canWrite,
+		dto_validator.ValidateIdMiddleware(),
+		dto_validator.ValidateUpdateCardMiddleware(deps.Repos.Characteristic),
+		deps.Handlers.Card.UpdateCard,
+	)
+	app.Patch("/cards/:id",
+		jwtAuth,
+		canWrite,
+		dto_validator.ValidateIdMiddleware(),
+		dto_validator.ValidatePatchCardMiddleware(deps.Repos.Characteristic),
+		deps.Handlers.Card.PatchCard,
 	)
 	app.Post("/cards/search",
+		optionalAuth,
+		dto_validator.ValidateIncludeRemovedMiddleware(),
 		dto_validator.ValidateGetCardsByVectorMiddleware(),
-		handlers.CardHandler.GetCardsByVector,
+		deps.Handlers.Card.GetCardsByVector,
 	)
 }
@@ -2,31 +2,42 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"shop/internal/api/handlers"
+	"shop/internal/api/middlewares/auth"
 	"shop/internal/api/middlewares/validator/dto_validator"
+	"shop/internal/container"
+	"shop/internal/model"
 )
 
-func RegisterCharDefaultValueRoutes(app fiber.Router) {
+func RegisterCharDefaultValueRoutes(app fiber.Router, deps *container.Container) {
+	jwtAuth := auth.JwtAuthMiddleware(deps.Services.JWT)
+	canWrite := auth.RequirePermission(model.PermCharacteristicsWrite)
+
 	app.Get("/selectors",
 		dto_validator.ValidatePaginationMiddleware(),
-		handlers.CharDefaultValueHandler.GetAllDefValue,
+		deps.Handlers.CharDefaultValue.GetAllDefValue,
 	)
 
 	app.Get("/selectors/:id",
 		dto_validator.ValidateIdMiddleware(),
-		handlers.CharDefaultValueHandler.GetDefValueById,
+		deps.Handlers.CharDefaultValue.GetDefValueById,
 	)
 	app.Post("/selectors",
+		jwtAuth,
+		canWrite,
 		dto_validator.ValidateCreateCharDefaultValueMiddleware(),
-		handlers.CharDefaultValueHandler.CreateDefValue,
+		deps.Handlers.CharDefaultValue.CreateDefValue,
 	)
 
 	app.Put("/selectors",
+		jwtAuth,
+		canWrite,
 		dto_validator.ValidateUpdateCharDefValueMiddleware(),
-		handlers.CharDefaultValueHandler.UpdateDefValue,
+		deps.Handlers.CharDefaultValue.UpdateDefValue,
 	)
 	app.Delete("/selectors/:id",
+		jwtAuth,
+		canWrite,
 		dto_validator.ValidateIdMiddleware(),
-		handlers.CharDefaultValueHandler.DeleteDefValue,
+		deps.Handlers.CharDefaultValue.DeleteDefValue,
 	)
 }
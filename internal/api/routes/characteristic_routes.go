@@ -2,30 +2,44 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"shop/internal/api/handlers"
+	"shop/internal/api/middlewares/auth"
 	"shop/internal/api/middlewares/validator/dto_validator"
+	"shop/internal/container"
+	"shop/internal/model"
 )
 
-func RegisterCharacteristicRoutes(app fiber.Router) {
+func RegisterCharacteristicRoutes(app fiber.Router, deps *container.Container) {
+	jwtAuth := auth.JwtAuthMiddleware(deps.Services.JWT)
+	optionalAuth := auth.OptionalJwtAuthMiddleware(deps.Services.JWT)
+	canWrite := auth.RequirePermission(model.PermCharacteristicsWrite)
+
 	app.Get("/characteristics",
 		dto_validator.ValidatePaginationMiddleware(),
-		handlers.CharacteristicHandler.GetAllCharacteristics,
+		deps.Handlers.Characteristic.GetAllCharacteristics,
 	)
 	app.Get("/characteristics/filters",
-		dto_validator.ValidateNodeTypeIdMiddleware(),
-		handlers.CharacteristicHandler.GetCharForFilters,
+		optionalAuth,
+		dto_validator.ValidateIncludeRemovedMiddleware(),
+		dto_validator.ValidateNodeTypeIdMiddleware(deps.Repos.NodeType),
+		deps.Handlers.Characteristic.GetCharForFilters,
 	)
 	app.Post("/characteristics",
+		jwtAuth,
+		canWrite,
 		dto_validator.ValidateCreateCharacteristicMiddleware(),
-		handlers.CharacteristicHandler.CreateCharacteristic,
+		deps.Handlers.Characteristic.CreateCharacteristic,
 	)
 
 	app.Put("/characteristics",
+		jwtAuth,
+		canWrite,
 		dto_validator.ValidateUpdateCharacteristicMiddleware(),
-		handlers.CharacteristicHandler.UpdateCharacteristic,
+		deps.Handlers.Characteristic.UpdateCharacteristic,
 	)
 	app.Delete("/characteristics/:id",
+		jwtAuth,
+		canWrite,
 		dto_validator.ValidateIdMiddleware(),
-		handlers.CharacteristicHandler.DeleteCharacteristic,
+		deps.Handlers.Characteristic.DeleteCharacteristic,
 	)
 }
@@ -2,26 +2,51 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"shop/internal/api/handlers"
+	"shop/internal/api/middlewares/auth"
 	"shop/internal/api/middlewares/validator/dto_validator"
+	"shop/internal/container"
+	"shop/internal/model"
 )
 
-func RegisterNodeRoutes(app fiber.Router) {
+func RegisterNodeRoutes(app fiber.Router, deps *container.Container) {
+	jwtAuth := auth.JwtAuthMiddleware(deps.Services.JWT)
+	canWrite := auth.RequirePermission(model.PermCardsWrite)
+	canPurge := auth.RequirePermission(model.PermNodesPurge)
+
 	app.Get("/nodes",
 		dto_validator.ValidatePaginationMiddleware(),
-		handlers.NodeHandler.GetAllNode,
+		deps.Handlers.Node.GetAllNode,
 	)
 	app.Post("/nodes",
+		jwtAuth,
+		canWrite,
 		dto_validator.ValidateCreateNodeMiddleware(),
-		handlers.NodeHandler.CreateNode,
+		deps.Handlers.Node.CreateNode,
 	)
 
 	app.Put("/nodes",
+		jwtAuth,
+		canWrite,
 		dto_validator.ValidateUpdateNodeMiddleware(),
-		handlers.NodeHandler.UpdateNode,
+		deps.Handlers.Node.UpdateNode,
+	)
+	app.Post("/nodes/:id/restore",
+		jwtAuth,
+		canWrite,
+		dto_validator.ValidateIdMiddleware(),
+		deps.Handlers.Node.RestoreNode,
+	)
+	// Регистрируется раньше /nodes/:id, чтобы "removed" не разбирался как id
+	app.Delete("/nodes/removed",
+		jwtAuth,
+		canPurge,
+		dto_validator.ValidatePurgeNodesMiddleware(),
+		deps.Handlers.Node.PurgeRemovedNodes,
 	)
 	app.Delete("/nodes/:id",
+		jwtAuth,
+		canWrite,
 		dto_validator.ValidateIdMiddleware(),
-		handlers.NodeHandler.DeleteNode,
+		deps.Handlers.Node.DeleteNode,
 	)
 }
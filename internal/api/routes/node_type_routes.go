@@ -2,26 +2,37 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"shop/internal/api/handlers"
+	"shop/internal/api/middlewares/auth"
 	"shop/internal/api/middlewares/validator/dto_validator"
+	"shop/internal/container"
+	"shop/internal/model"
 )
 
-func RegisterNodeTypeRoutes(app fiber.Router) {
+func RegisterNodeTypeRoutes(app fiber.Router, deps *container.Container) {
+	jwtAuth := auth.JwtAuthMiddleware(deps.Services.JWT)
+	canWrite := auth.RequirePermission(model.PermNodeTypesWrite)
+
 	app.Get("/node-types",
 		dto_validator.ValidatePaginationMiddleware(),
-		handlers.NodeTypeHandler.GetAllNodeType,
+		deps.Handlers.NodeType.GetAllNodeType,
 	)
 	app.Post("/node-types",
+		jwtAuth,
+		canWrite,
 		dto_validator.ValidateCreateNodeTypeMiddleware(),
-		handlers.NodeTypeHandler.CreateNodeType,
+		deps.Handlers.NodeType.CreateNodeType,
 	)
 
 	app.Put("/node-types",
+		jwtAuth,
+		canWrite,
 		dto_validator.ValidateUpdateNodeTypeMiddleware(),
-		handlers.NodeTypeHandler.UpdateNodeType,
+		deps.Handlers.NodeType.UpdateNodeType,
 	)
 	app.Delete("/node-types/:id",
+		jwtAuth,
+		canWrite,
 		dto_validator.ValidateIdMiddleware(),
-		handlers.NodeTypeHandler.DeleteNodeType,
+		deps.Handlers.NodeType.DeleteNodeType,
 	)
 }
@@ -2,15 +2,49 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"shop/internal/api/handlers"
+	"shop/internal/api/middlewares/auth"
 	"shop/internal/api/middlewares/validator/dto_validator"
+	"shop/internal/container"
+	"shop/internal/model"
 )
 
-func RegisterOrderRoutes(app fiber.Router) {
+func RegisterOrderRoutes(app fiber.Router, deps *container.Container) {
+	jwtAuth := auth.JwtAuthMiddleware(deps.Services.JWT)
+	optionalAuth := auth.OptionalJwtAuthMiddleware(deps.Services.JWT)
+	// Право orders:read:own дополнительно ограничивается в обработчиках собственными заказами пользователя
+	canRead := auth.RequireAnyPermission(model.PermOrdersReadAny, model.PermOrdersReadOwn)
+	canWrite := auth.RequirePermission(model.PermOrdersWrite)
 
 	app.Post("/orders",
-		dto_validator.ValidateCreateOrderMiddleware(),
-		handlers.OrderHandler.CreateOrder,
+		optionalAuth,
+		dto_validator.ValidateCreateOrderMiddleware(deps.Repos.Node),
+		deps.Handlers.Order.CreateOrder,
+	)
+	app.Get("/orders",
+		jwtAuth,
+		canRead,
+		dto_validator.ValidatePaginationMiddleware(),
+		dto_validator.ValidateOrderFilterMiddleware(),
+		deps.Handlers.Order.GetAllOrders,
+	)
+	app.Get("/orders/:id",
+		jwtAuth,
+		canRead,
+		dto_validator.ValidateUUIDMiddleware(),
+		deps.Handlers.Order.GetOrderById,
+	)
+	app.Get("/orders/:id/history",
+		jwtAuth,
+		canRead,
+		dto_validator.ValidateUUIDMiddleware(),
+		deps.Handlers.Order.GetOrderStatusHistory,
+	)
+	app.Put("/orders/:id/status",
+		jwtAuth,
+		canWrite,
+		dto_validator.ValidateUUIDMiddleware(),
+		dto_validator.ValidateUpdateOrderStatusMiddleware(),
+		deps.Handlers.Order.UpdateOrderStatus,
 	)
 
 }
@@ -2,26 +2,37 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"shop/internal/api/handlers"
+	"shop/internal/api/middlewares/auth"
 	"shop/internal/api/middlewares/validator/dto_validator"
+	"shop/internal/container"
+	"shop/internal/model"
 )
 
-func RegisterSizeRoutes(app fiber.Router) {
+func RegisterSizeRoutes(app fiber.Router, deps *container.Container) {
+	jwtAuth := auth.JwtAuthMiddleware(deps.Services.JWT)
+	canWrite := auth.RequirePermission(model.PermSizesWrite)
+
 	app.Get("/sizes",
 		dto_validator.ValidatePaginationMiddleware(),
-		handlers.SizeHandler.GetAllSizes,
+		deps.Handlers.Size.GetAllSizes,
 	)
 	app.Post("/sizes",
+		jwtAuth,
+		canWrite,
 		dto_validator.ValidateCreateSizeMiddleware(),
-		handlers.SizeHandler.CreateSize,
+		deps.Handlers.Size.CreateSize,
 	)
 
 	app.Put("/sizes",
+		jwtAuth,
+		canWrite,
 		dto_validator.ValidateUpdateSizeMiddleware(),
-		handlers.SizeHandler.UpdateSize,
+		deps.Handlers.Size.UpdateSize,
 	)
 	app.Delete("/sizes/:id",
+		jwtAuth,
+		canWrite,
 		dto_validator.ValidateIdMiddleware(),
-		handlers.SizeHandler.DeleteSize,
+		deps.Handlers.Size.DeleteSize,
 	)
 }
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"shop/internal/api/middlewares/auth"
+	"shop/internal/api/middlewares/validator/dto_validator"
+	"shop/internal/container"
+	"shop/internal/model"
+)
+
+func RegisterStockRoutes(app fiber.Router, deps *container.Container) {
+	jwtAuth := auth.JwtAuthMiddleware(deps.Services.JWT)
+	canRead := auth.RequirePermission(model.PermStockRead)
+	canWrite := auth.RequirePermission(model.PermStockWrite)
+
+	app.Get("/stock",
+		jwtAuth,
+		canRead,
+		dto_validator.ValidatePaginationMiddleware(),
+		dto_validator.ValidateNodeIdQueryMiddleware(),
+		deps.Handlers.Stock.GetAllStock,
+	)
+	app.Post("/stock",
+		jwtAuth,
+		canWrite,
+		dto_validator.ValidateCreateStockMiddleware(),
+		deps.Handlers.Stock.CreateStock,
+	)
+
+	app.Put("/stock",
+		jwtAuth,
+		canWrite,
+		dto_validator.ValidateUpdateStockMiddleware(),
+		deps.Handlers.Stock.UpdateStock,
+	)
+	app.Delete("/stock/:id",
+		jwtAuth,
+		canWrite,
+		dto_validator.ValidateIdMiddleware(),
+		deps.Handlers.Stock.DeleteStock,
+	)
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"shop/internal/api/middlewares/auth"
+	"shop/internal/api/middlewares/validator/dto_validator"
+	"shop/internal/container"
+	"shop/internal/model"
+)
+
+func RegisterUserRoutes(app fiber.Router, deps *container.Container) {
+	jwtAuth := auth.JwtAuthMiddleware(deps.Services.JWT)
+	canWrite := auth.RequirePermission(model.PermUsersWrite)
+
+	app.Put("/users/:id/role",
+		jwtAuth,
+		canWrite,
+		dto_validator.ValidateIdMiddleware(),
+		dto_validator.ValidateUpdateUserRoleMiddleware(),
+		deps.Handlers.User.UpdateUserRole,
+	)
+}
@@ -0,0 +1,127 @@
+package container
+
+import (
+	"database/sql"
+	"shop/internal/api/handlers"
+	"shop/internal/repository"
+	"shop/internal/service"
+)
+
+// Container — зависимости приложения, собранные вокруг одного подключения к БД.
+// Каждый вызов New создаёт независимый набор репозиториев, сервисов и хендлеров,
+// поэтому в тестах можно поднять несколько экземпляров или подменить отдельные зависимости.
+type Container struct {
+	DB       *sql.DB
+	Repos    Repositories
+	Services Services
+	Handlers Handlers
+}
+
+type Repositories struct {
+	Tx               repository.TxManager
+	Card             repository.CardRepositoryInterface
+	CharDefaultValue repository.CharDefaultValueInterface
+	Characteristic   repository.CharacteristicRepositoryInterface
+	Node             repository.NodeRepositoryInterface
+	NodeType         repository.NodeTypeRepositoryInterface
+	Order            repository.OrderRepositoryInterface
+	RefreshToken     repository.RefreshTokenRepositoryInterface
+	Size             repository.SizeRepositoryInterface
+	Stock            repository.StockRepositoryInterface
+	Suggest          repository.SuggestRepositoryInterface
+	User             repository.UserRepositoryInterface
+}
+
+type Services struct {
+	JWT              service.JWTServiceInterface
+	User             service.UserServiceInterface
+	Auth             service.AuthServiceInterface
+	Card             service.CardServiceInterface
+	CharDefaultValue service.CharDefaultValueServiceInterface
+	Characteristic   service.CharacteristicServiceInterface
+	Node             service.NodeServiceInterface
+	NodeType         service.NodeTypeServiceInterface
+	Order            service.OrderServiceInterface
+	Size             service.SizeServiceInterface
+	Stock            service.StockServiceInterface
+	Suggest          service.SuggestServiceInterface
+}
+
+type Handlers struct {
+	Auth             handlers.AuthHandlerInterface
+	Card             handlers.CardHandlerInterface
+	CharDefaultValue handlers.CharDefaultValueHandlerInterface
+	Characteristic   handlers.CharacteristicHandlerInterface
+	Log              handlers.LogHandlerInterface
+	Node             handlers.NodeHandlerInterface
+	NodeType         handlers.NodeTypeHandlerInterface
+	Order            handlers.OrderHandlerInterface
+	Size             handlers.SizeHandlerInterface
+	Stock            handlers.StockHandlerInterface
+	User             handlers.UserHandlerInterface
+}
+
+// New собирает приложение поверх db.
+func New(db *sql.DB) *Container {
+	c := &Container{DB: db}
+	c.Repos = NewRepositories(db)
+	c.Services = NewServices(c.Repos)
+	c.Handlers = NewHandlers(c.Services)
+	return c
+}
+
+// NewRepositories создаёт репозитории, работающие с db.
+func NewRepositories(db *sql.DB) Repositories {
+	return Repositories{
+		Tx:               repository.NewTxManager(db),
+		Card:             repository.NewCardRepository(db),
+		CharDefaultValue: repository.NewCharDefaultValueRepository(db),
+		Characteristic:   repository.NewCharacteristicRepository(db),
+		Node:             repository.NewNodeRepository(db),
+		NodeType:         repository.NewNodeTypeRepository(db),
+		Order:            repository.NewOrderRepository(db),
+		RefreshToken:     repository.NewRefreshTokenRepository(db),
+		Size:             repository.NewSizeRepository(db),
+		Stock:            repository.NewStockRepository(db),
+		Suggest:          repository.NewSuggestRepository(db),
+		User:             repository.NewUserRepository(db),
+	}
+}
+
+// NewServices создаёт сервисы поверх переданных репозиториев (в том числе подменённых в тестах).
+func NewServices(repos Repositories) Services {
+	jwtService := service.NewJWTService()
+	userService := service.NewUserService(repos.User)
+
+	return Services{
+		JWT:              jwtService,
+		User:             userService,
+		Auth:             service.NewAuthService(repos.RefreshToken, userService, jwtService),
+		Card:             service.NewCardService(repos.Tx, repos.Card, repos.Characteristic, repos.Node, repos.NodeType),
+		CharDefaultValue: service.NewCharDefaultValueService(repos.CharDefaultValue),
+		Characteristic:   service.NewCharacteristicService(repos.Tx, repos.Characteristic),
+		Node:             service.NewNodeService(repos.Node, repos.NodeType),
+		NodeType:         service.NewNodeTypeService(repos.NodeType),
+		Order:            service.NewOrderService(repos.Tx, repos.Order),
+		Size:             service.NewSizeService(repos.Size),
+		Stock:            service.NewStockService(repos.Tx, repos.Stock, repos.Node, repos.Size),
+		Suggest:          service.NewSuggestService(repos.Suggest),
+	}
+}
+
+// NewHandlers создаёт HTTP-хендлеры поверх переданных сервисов.
+func NewHandlers(services Services) Handlers {
+	return Handlers{
+		Auth:             handlers.NewAuthHandler(services.Auth, services.User),
+		Card:             handlers.NewCardHandler(services.Card, services.Suggest),
+		CharDefaultValue: handlers.NewCharDefaultValueHandler(services.CharDefaultValue),
+		Characteristic:   handlers.NewCharacteristicHandler(services.Characteristic),
+		Log:              handlers.NewLogHandler(),
+		Node:             handlers.NewNodeHandler(services.Node),
+		NodeType:         handlers.NewNodeTypeHandler(services.NodeType),
+		Order:            handlers.NewOrderHandler(services.Order),
+		Size:             handlers.NewSizeHandler(services.Size),
+		Stock:            handlers.NewStockHandler(services.Stock),
+		User:             handlers.NewUserHandler(services.User),
+	}
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type TokenResponse struct {
+	AccessToken      string `json:"accessToken"`
+	TokenType        string `json:"tokenType"`
+	ExpiresIn        int    `json:"expiresIn"`
+	RefreshToken     string `json:"refreshToken"`
+	RefreshExpiresIn int    `json:"refreshExpiresIn"`
+}
+
+// RefreshTokenRow — запись о выданном refresh-токене. Сам токен не хранится, только его bcrypt-хеш.
+// Все токены, полученные ротацией из одного входа, имеют общий FamilyId.
+type RefreshTokenRow struct {
+	ID        string     `db:"id"`
+	FamilyId  string     `db:"family_id"`
+	UserId    int        `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	RotatedAt *time.Time `db:"rotated_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
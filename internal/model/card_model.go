@@ -4,10 +4,63 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 )
 
+// Сортировки списка карточек.
+const (
+	CardSortNewest    = "newest"
+	CardSortPriceAsc  = "price_asc"
+	CardSortPriceDesc = "price_desc"
+	CardSortTitle     = "title"
+	CardSortDiscount  = "discount"
+	CardSortRelevance = "relevance"
+)
+
+// Валюты цены карточки.
+const (
+	CurrencyByn = "byn"
+	CurrencyRub = "rub"
+)
+
+// DiscountCharTitle — название характеристики, в которой хранится скидка товара в процентах.
+const DiscountCharTitle = "Скидка"
+
+// DiscountPattern — разбор значения скидки: целое число процентов (до трёх цифр) в начале значения,
+// например "15", " 15 %" или "10.5%" (дробная часть отбрасывается). Тот же шаблон используется
+// в SQL (substring(... from pattern)), чтобы список карточек, сортировка и заказы видели одну скидку.
+const DiscountPattern = `^\s*(\d{1,3})(?:[^0-9]|$)`
+
+var discountRegexp = regexp.MustCompile(DiscountPattern)
+
+// ParseDiscount возвращает скидку в процентах из значения характеристики "Скидка".
+// Если значение не начинается с числа из не более чем трёх цифр, возвращается ok == false.
+func ParseDiscount(value string) (discount int, ok bool) {
+	match := discountRegexp.FindStringSubmatch(value)
+	if match == nil {
+		return 0, false
+	}
+	discount, err := strconv.Atoi(match[1])
+	return discount, err == nil
+}
+
+var cardSorts = map[string]struct{}{
+	CardSortNewest:    {},
+	CardSortPriceAsc:  {},
+	CardSortPriceDesc: {},
+	CardSortTitle:     {},
+	CardSortDiscount:  {},
+	CardSortRelevance: {},
+}
+
+// IsValidCardSort сообщает, поддерживается ли такая сортировка списка карточек.
+func IsValidCardSort(sort string) bool {
+	_, ok := cardSorts[sort]
+	return ok
+}
+
 type CardRow struct {
 	NodeId                    int              `db:"nodeId" json:"nodeId"`
 	Title                     string           `db:"title" json:"title"`
@@ -24,6 +77,9 @@ type CardRow struct {
 	CharacteristicValue       string           `db:"characteristicValue" json:"characteristicValue"`
 	AdditionalParams          *json.RawMessage `db:"additionalParams" json:"additionalParams"`
 	CharacteristicDescription *string          `db:"characteristicDescription" json:"characteristicDescription"`
+	Rank                      *float64         `db:"rank" json:"rank"`
+	TitleHighlight            *string          `db:"titleHighlight" json:"titleHighlight"`
+	DescriptionHighlight      *string          `db:"descriptionHighlight" json:"descriptionHighlight"`
 }
 type CardResponse struct {
 	NodeId              int                `json:"nodeId"`
@@ -39,6 +95,23 @@ type CardResponse struct {
 	NodeType            string             `json:"nodeType"`
 	NodeTypeDescription *string            `json:"nodeTypeDescription"`
 	Characteristics     [][]Characteristic `json:"characteristics"`
+	Rank                *float64           `json:"rank,omitempty"`
+	Highlight           *CardHighlight     `json:"highlight,omitempty"`
+}
+
+// CardHighlight — фрагменты названия и описания с найденными словами, обёрнутыми в <mark>.
+// Заполняется только при полнотекстовом поиске (q).
+type CardHighlight struct {
+	Title       string  `json:"title"`
+	Description *string `json:"description"`
+}
+
+// highlight возвращает подсветку поиска для строки карточки или nil, если поиска не было.
+func (r CardRow) highlight() *CardHighlight {
+	if r.TitleHighlight == nil {
+		return nil
+	}
+	return &CardHighlight{Title: *r.TitleHighlight, Description: r.DescriptionHighlight}
 }
 
 type Characteristic struct {
@@ -48,9 +121,75 @@ type Characteristic struct {
 	CharacteristicDescription *string                 `json:"description"`
 }
 
+// CardFilter — разобранные фильтры списка карточек. Пустые поля не ограничивают выборку.
+// Query — строка полнотекстового поиска, SearchConfig — конфигурация поиска для неё
+// (пустая — конфигурация типа каждой ноды).
+// Currency определяет, к какой цене (BYN или RUB) применяются PriceMin/PriceMax и ценовая сортировка.
 type CardFilter struct {
-	Key    string
-	Values string
+	Query           string
+	SearchConfig    string
+	Currency        string
+	NodeTypeIds     []int
+	PriceMin        *int
+	PriceMax        *int
+	Sale            *bool
+	Characteristics []CharacteristicFilter
+}
+
+// CharacteristicFilter — условие по одной характеристике. Значения и диапазоны объединяются через OR,
+// условия по разным характеристикам — через AND. Если не задано ни значений, ни диапазонов,
+// проверяется только наличие характеристики у ноды. Negate инвертирует условие.
+type CharacteristicFilter struct {
+	CharacteristicId int
+	Values           []string
+	Ranges           []ValueRange
+	Negate           bool
+}
+
+// WithoutCharacteristic возвращает копию фильтра без выбранных значений характеристики characteristicId.
+// Отрицания (not:) сохраняются: они исключают товары, а не выбирают вариант фасета.
+func (f *CardFilter) WithoutCharacteristic(characteristicId int) *CardFilter {
+	result := *f
+	result.Characteristics = make([]CharacteristicFilter, 0, len(f.Characteristics))
+	for _, charFilter := range f.Characteristics {
+		if charFilter.CharacteristicId == characteristicId && !charFilter.Negate {
+			continue
+		}
+		result.Characteristics = append(result.Characteristics, charFilter)
+	}
+	return &result
+}
+
+// WithoutNodeTypes возвращает копию фильтра без условия по типам нод.
+func (f *CardFilter) WithoutNodeTypes() *CardFilter {
+	result := *f
+	result.NodeTypeIds = nil
+	return &result
+}
+
+// WithoutPrice возвращает копию фильтра без границ цены.
+func (f *CardFilter) WithoutPrice() *CardFilter {
+	result := *f
+	result.PriceMin = nil
+	result.PriceMax = nil
+	return &result
+}
+
+// ValueRange — числовой диапазон значений характеристики; nil-граница не ограничивает.
+type ValueRange struct {
+	Min *float64
+	Max *float64
+}
+
+// CardCursor — позиция в отсортированном списке карточек: сортировка, валюта, значение ключа сортировки
+// последней карточки страницы (в текстовом виде) и её id. Клиенту отдаётся в виде непрозрачной
+// строки (см. utils.EncodeCursor) и действителен только для той же сортировки и валюты:
+// при ценовой сортировке от валюты зависит колонка ключа.
+type CardCursor struct {
+	Sort     string `json:"sort"`
+	Currency string `json:"currency"`
+	Key      string `json:"key"`
+	ID       int    `json:"id"`
 }
 
 // MapperCardResponse maps rows of CardRow to grouped CardResponse slices.
@@ -66,7 +205,9 @@ func MapperCardResponse(rows *[]CardRow) ([]CardResponse, error) {
 	}
 
 	// Используем карту, где ключ – NodeId, а значение – указатель на cardGroup.
+	// order хранит NodeId в порядке первого появления, чтобы сохранить сортировку запроса.
 	m := make(map[int]*cardGroup)
+	var order []int
 
 	for _, row := range *rows {
 		nodeId := row.NodeId
@@ -88,13 +229,14 @@ func MapperCardResponse(rows *[]CardRow) ([]CardResponse, error) {
 		}
 
 		// Обработка скидки: если название характеристики "Скидка", сохраняем в поле Sale и пропускаем группировку.
-		if strings.EqualFold(addParam.Title, "Скидка") {
-			saleVal, err := strconv.Atoi(addParam.Value)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse discount for NodeId %d: %w", nodeId, err)
+		// Значение без числа в начале (см. ParseDiscount) считается отсутствием скидки, как и в SQL.
+		if strings.EqualFold(addParam.Title, DiscountCharTitle) {
+			var sale *int
+			if saleVal, ok := ParseDiscount(addParam.Value); ok {
+				sale = &saleVal
 			}
 			if cg, exist := m[nodeId]; exist {
-				cg.card.Sale = &saleVal
+				cg.card.Sale = sale
 			} else {
 				newCard := CardResponse{
 					NodeId:              row.NodeId,
@@ -105,15 +247,18 @@ func MapperCardResponse(rows *[]CardRow) ([]CardResponse, error) {
 					RemovedAt:           row.RemovedAt,
 					PriceByn:            row.PriceByn,
 					PriceRub:            row.PriceRub,
-					Sale:                &saleVal,
+					Sale:                sale,
 					Images:              row.Images,
 					NodeType:            row.NodeType,
 					NodeTypeDescription: row.NodeTypeDescription,
+					Rank:                row.Rank,
+					Highlight:           row.highlight(),
 				}
 				m[nodeId] = &cardGroup{
 					card:   newCard,
 					groups: make(map[string][]Characteristic),
 				}
+				order = append(order, nodeId)
 			}
 			continue
 		}
@@ -135,6 +280,8 @@ func MapperCardResponse(rows *[]CardRow) ([]CardResponse, error) {
 				Images:              row.Images,
 				NodeType:            row.NodeType,
 				NodeTypeDescription: row.NodeTypeDescription,
+				Rank:                row.Rank,
+				Highlight:           row.highlight(),
 			}
 			groups := make(map[string][]Characteristic)
 			groups[addParam.Title] = []Characteristic{addParam}
@@ -142,12 +289,14 @@ func MapperCardResponse(rows *[]CardRow) ([]CardResponse, error) {
 				card:   newCard,
 				groups: groups,
 			}
+			order = append(order, nodeId)
 		}
 	}
 
 	// Формируем итоговый срез карточек, где характеристики сгруппированы в подмассивы.
 	result := make([]CardResponse, 0, len(m))
-	for _, cg := range m {
+	for _, nodeId := range order {
+		cg := m[nodeId]
 		// Преобразуем карту групп в срез срезов.
 		var groupedCharacteristics [][]Characteristic
 		for _, chars := range cg.groups {
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseDiscount(t *testing.T) {
+	tests := []struct {
+		value    string
+		discount int
+		ok       bool
+	}{
+		{"15", 15, true},
+		{" 15 %", 15, true},
+		{"15%", 15, true},
+		{"10.5%", 10, true},
+		{"100", 100, true},
+		{"0", 0, true},
+		{"1000", 0, false},
+		{"99999999999", 0, false},
+		{"-10", 0, false},
+		{"скидка 10", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			discount, ok := ParseDiscount(tt.value)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.discount, discount)
+		})
+	}
+}
+
+func TestMapperCardResponseDiscount(t *testing.T) {
+	rows := []CardRow{
+		{NodeId: 1, Title: "Платье", Characteristic: "Цвет", CharacteristicValue: "Red"},
+		{NodeId: 1, Title: "Платье", Characteristic: DiscountCharTitle, CharacteristicValue: "10.5%"},
+		{NodeId: 2, Title: "Юбка", Characteristic: DiscountCharTitle, CharacteristicValue: "нет"},
+	}
+
+	cards, err := MapperCardResponse(&rows)
+	require.NoError(t, err)
+	require.Len(t, cards, 2)
+
+	if assert.NotNil(t, cards[0].Sale) {
+		assert.Equal(t, 10, *cards[0].Sale)
+	}
+	assert.Len(t, cards[0].Characteristics, 1)
+
+	// Значение без числа — не ошибка всего списка, а отсутствие скидки
+	assert.Nil(t, cards[1].Sale)
+}
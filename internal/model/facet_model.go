@@ -0,0 +1,48 @@
+package model
+
+// DefaultFacetPriceStep — ширина ценового интервала фасетов по умолчанию.
+const DefaultFacetPriceStep = 50
+
+// CharValueCountRow — количество нод с данным значением характеристики.
+type CharValueCountRow struct {
+	CharacteristicId int    `db:"characteristicId" json:"characteristicId"`
+	Value            string `db:"value" json:"value"`
+	Count            int    `db:"count" json:"count"`
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type CharacteristicFacet struct {
+	CharacteristicId int          `json:"characteristicId"`
+	Title            string       `json:"title"`
+	Description      *string      `json:"description"`
+	Values           []FacetValue `json:"values"`
+}
+
+type NodeTypeFacet struct {
+	NodeTypeId int    `db:"nodeTypeId" json:"nodeTypeId"`
+	Type       string `db:"type" json:"type"`
+	Count      int    `db:"count" json:"count"`
+}
+
+// PriceBucketFacet — ценовой интервал [Min, Max]; границы включительные и подставляются
+// в priceMin/priceMax как есть.
+type PriceBucketFacet struct {
+	Min   int `db:"min" json:"min"`
+	Max   int `db:"max" json:"max"`
+	Count int `db:"count" json:"count"`
+}
+
+// CardFacetsResponse — количество нод по значениям характеристик, типам нод и ценовым интервалам
+// для текущего фильтра списка карточек.
+type CardFacetsResponse struct {
+	Total           int                   `json:"total"`
+	Currency        string                `json:"currency"`
+	PriceStep       int                   `json:"priceStep"`
+	Characteristics []CharacteristicFacet `json:"characteristics"`
+	NodeTypes       []NodeTypeFacet       `json:"nodeTypes"`
+	PriceBuckets    []PriceBucketFacet    `json:"priceBuckets"`
+}
@@ -9,3 +9,10 @@ type NodeRow struct {
 	UpdatedAt   string  `db:"updated_at" json:"updatedAt"`
 	RemovedAt   *string `db:"removed_at" json:"removedAt"`
 }
+
+// PurgeNodesResponse — результат физического удаления нод, помеченных удалёнными.
+type PurgeNodesResponse struct {
+	RemovedBefore string `json:"removedBefore"`
+	PurgedCount   int    `json:"purgedCount"`
+	PurgedIds     []int  `json:"purgedIds"`
+}
@@ -1,7 +1,33 @@
 package model
 
+// Конфигурации полнотекстового поиска PostgreSQL, которые можно назначить типу ноды
+// или передать в запросе списка карточек (searchConfig).
+const (
+	SearchConfigRussian    = "russian"
+	SearchConfigEnglish    = "english"
+	SearchConfigBelarusian = "belarusian"
+	SearchConfigSimple     = "simple"
+)
+
+// DefaultSearchConfig — конфигурация поиска типа ноды по умолчанию.
+const DefaultSearchConfig = SearchConfigRussian
+
+var searchConfigs = map[string]struct{}{
+	SearchConfigRussian:    {},
+	SearchConfigEnglish:    {},
+	SearchConfigBelarusian: {},
+	SearchConfigSimple:     {},
+}
+
+// IsValidSearchConfig сообщает, поддерживается ли такая конфигурация поиска.
+func IsValidSearchConfig(config string) bool {
+	_, ok := searchConfigs[config]
+	return ok
+}
+
 type NodeTypeRow struct {
-	ID          int     `db:"id" json:"id"`
-	Type        string  `db:"type" json:"type"`
-	Description *string `db:"description" json:"description"`
+	ID           int     `db:"id" json:"id"`
+	Type         string  `db:"type" json:"type"`
+	Description  *string `db:"description" json:"description"`
+	SearchConfig string  `db:"search_config" json:"searchConfig"`
 }
@@ -1 +1,118 @@
 package model
+
+import "time"
+
+// Статусы заказа.
+const (
+	OrderStatusNew       = "new"
+	OrderStatusConfirmed = "confirmed"
+	OrderStatusPaid      = "paid"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusReturned  = "returned"
+)
+
+// orderTransitions описывает допустимые переходы между статусами заказа.
+// Статусы cancelled и returned конечные — из них перейти никуда нельзя.
+var orderTransitions = map[string][]string{
+	OrderStatusNew:       {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusConfirmed: {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:      {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:   {OrderStatusDelivered, OrderStatusReturned},
+	OrderStatusDelivered: {OrderStatusReturned},
+	OrderStatusCancelled: {},
+	OrderStatusReturned:  {},
+}
+
+// IsValidOrderStatus сообщает, известен ли такой статус заказа.
+func IsValidOrderStatus(status string) bool {
+	_, ok := orderTransitions[status]
+	return ok
+}
+
+// CanTransitionOrder сообщает, можно ли перевести заказ из статуса from в статус to.
+func CanTransitionOrder(from, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+type OrderRow struct {
+	ID        string `db:"id" json:"id"`
+	UserId    *int   `db:"user_id" json:"userId"`
+	Status    string `db:"status" json:"status"`
+	CreatedAt string `db:"created_at" json:"createdAt"`
+	UpdatedAt string `db:"updated_at" json:"updatedAt"`
+}
+
+type OrderItemRow struct {
+	ID       int     `db:"id" json:"id"`
+	OrderId  string  `db:"order_id" json:"orderId"`
+	NodeId   int     `db:"node_id" json:"nodeId"`
+	Title    string  `db:"title" json:"title"`
+	Size     *string `db:"size" json:"size"`
+	Amount   int     `db:"amount" json:"amount"`
+	PriceByn *int    `db:"price_byn" json:"priceByn"`
+	PriceRub *int    `db:"price_rub" json:"priceRub"`
+	Discount *int    `db:"discount" json:"discount"`
+}
+
+type OrderStatusHistoryRow struct {
+	ID         int     `db:"id" json:"id"`
+	OrderId    string  `db:"order_id" json:"orderId"`
+	FromStatus *string `db:"from_status" json:"fromStatus"`
+	ToStatus   string  `db:"to_status" json:"toStatus"`
+	ChangedBy  *string `db:"changed_by" json:"changedBy"`
+	Comment    *string `db:"comment" json:"comment"`
+	ChangedAt  string  `db:"changed_at" json:"changedAt"`
+}
+
+// OrderFilter — параметры выборки списка заказов. Пустые поля не ограничивают выборку.
+type OrderFilter struct {
+	UserId   *int
+	Statuses []string
+	DateFrom *time.Time
+	DateTo   *time.Time
+}
+
+type OrderResponse struct {
+	OrderRow
+	TotalByn int            `json:"totalByn"`
+	TotalRub int            `json:"totalRub"`
+	Items    []OrderItemRow `json:"items"`
+}
+
+// MapperOrderResponse собирает заказ и его позиции в один ответ и считает итоговые суммы с учётом скидки.
+func MapperOrderResponse(order *OrderRow, items []OrderItemRow) *OrderResponse {
+	result := &OrderResponse{
+		OrderRow: *order,
+		Items:    items,
+	}
+
+	if result.Items == nil {
+		result.Items = make([]OrderItemRow, 0)
+	}
+
+	for _, item := range result.Items {
+		result.TotalByn += item.lineTotal(item.PriceByn)
+		result.TotalRub += item.lineTotal(item.PriceRub)
+	}
+
+	return result
+}
+
+// lineTotal возвращает стоимость позиции (цена * количество) за вычетом скидки в процентах.
+func (i OrderItemRow) lineTotal(price *int) int {
+	if price == nil {
+		return 0
+	}
+	total := *price * i.Amount
+	if i.Discount != nil && *i.Discount > 0 && *i.Discount <= 100 {
+		total = total * (100 - *i.Discount) / 100
+	}
+	return total
+}
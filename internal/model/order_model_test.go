@@ -0,0 +1,107 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCanTransitionOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want bool
+	}{
+		{"new to confirmed", OrderStatusNew, OrderStatusConfirmed, true},
+		{"new to cancelled", OrderStatusNew, OrderStatusCancelled, true},
+		{"new to paid skips confirmation", OrderStatusNew, OrderStatusPaid, false},
+		{"confirmed to paid", OrderStatusConfirmed, OrderStatusPaid, true},
+		{"paid to shipped", OrderStatusPaid, OrderStatusShipped, true},
+		{"shipped to delivered", OrderStatusShipped, OrderStatusDelivered, true},
+		{"shipped to returned", OrderStatusShipped, OrderStatusReturned, true},
+		{"shipped cannot be cancelled", OrderStatusShipped, OrderStatusCancelled, false},
+		{"delivered to returned", OrderStatusDelivered, OrderStatusReturned, true},
+		{"cancelled is final", OrderStatusCancelled, OrderStatusNew, false},
+		{"returned is final", OrderStatusReturned, OrderStatusDelivered, false},
+		{"same status", OrderStatusNew, OrderStatusNew, false},
+		{"unknown from", "lost", OrderStatusConfirmed, false},
+		{"unknown to", OrderStatusNew, "lost", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CanTransitionOrder(tt.from, tt.to))
+		})
+	}
+}
+
+func TestMapperOrderResponseTotals(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name     string
+		items    []OrderItemRow
+		totalByn int
+		totalRub int
+	}{
+		{
+			name: "no items",
+		},
+		{
+			name:     "price times amount",
+			items:    []OrderItemRow{{Amount: 3, PriceByn: intPtr(100), PriceRub: intPtr(3000)}},
+			totalByn: 300,
+			totalRub: 9000,
+		},
+		{
+			name:     "discount in percent",
+			items:    []OrderItemRow{{Amount: 2, PriceByn: intPtr(150), PriceRub: intPtr(4000), Discount: intPtr(20)}},
+			totalByn: 240,
+			totalRub: 6400,
+		},
+		{
+			name:     "discount rounds down",
+			items:    []OrderItemRow{{Amount: 1, PriceByn: intPtr(99), Discount: intPtr(50)}},
+			totalByn: 49,
+		},
+		{
+			name: "discount out of range is ignored",
+			items: []OrderItemRow{
+				{Amount: 1, PriceByn: intPtr(100), Discount: intPtr(0)},
+				{Amount: 1, PriceByn: intPtr(100), Discount: intPtr(-10)},
+				{Amount: 1, PriceByn: intPtr(100), Discount: intPtr(150)},
+			},
+			totalByn: 300,
+		},
+		{
+			name:     "full discount",
+			items:    []OrderItemRow{{Amount: 5, PriceByn: intPtr(100), Discount: intPtr(100)}},
+			totalByn: 0,
+		},
+		{
+			name:     "missing price counts as zero",
+			items:    []OrderItemRow{{Amount: 2, PriceByn: intPtr(10)}},
+			totalByn: 20,
+			totalRub: 0,
+		},
+		{
+			name: "several items",
+			items: []OrderItemRow{
+				{Amount: 1, PriceByn: intPtr(100), PriceRub: intPtr(3000)},
+				{Amount: 2, PriceByn: intPtr(50), PriceRub: intPtr(1500), Discount: intPtr(10)},
+			},
+			totalByn: 190,
+			totalRub: 5700,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := MapperOrderResponse(&OrderRow{ID: "order"}, tt.items)
+
+			assert.Equal(t, tt.totalByn, result.TotalByn)
+			assert.Equal(t, tt.totalRub, result.TotalRub)
+			assert.NotNil(t, result.Items)
+		})
+	}
+}
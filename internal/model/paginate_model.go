@@ -6,4 +6,7 @@ type Paginate[T any] struct {
 	TotalPageCount int `json:"totalPageCount"`
 	PageSize       int `json:"pageSize"`
 	Items          []T `json:"items"`
+	// NextCursor — курсор следующей страницы для keyset-пагинации; nil, если страница последняя
+	// или эндпоинт курсоры не поддерживает.
+	NextCursor *string `json:"nextCursor,omitempty"`
 }
@@ -0,0 +1,66 @@
+package model
+
+// Права доступа. Формат: <ресурс>:<действие>[:<область>].
+const (
+	PermCardsWrite           = "cards:write"
+	PermCardsReadRemoved     = "cards:read:removed"
+	PermNodesPurge           = "nodes:purge"
+	PermCharacteristicsWrite = "characteristics:write"
+	PermNodeTypesWrite       = "node_types:write"
+	PermSizesWrite           = "sizes:write"
+	PermStockRead            = "stock:read"
+	PermStockWrite           = "stock:write"
+	PermOrdersReadAny        = "orders:read:any"
+	PermOrdersReadOwn        = "orders:read:own"
+	PermOrdersWrite          = "orders:write"
+	PermUsersWrite           = "users:write"
+	PermLogsWrite            = "logs:write"
+)
+
+// rolePermissions описывает, какие права выданы каждой роли.
+// Менеджер редактирует карточки, остатки и заказы, но не справочники (характеристики, типы нод, размеры).
+// Покупатель видит только собственные заказы.
+var rolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermCardsWrite,
+		PermCardsReadRemoved,
+		PermNodesPurge,
+		PermCharacteristicsWrite,
+		PermNodeTypesWrite,
+		PermSizesWrite,
+		PermStockRead,
+		PermStockWrite,
+		PermOrdersReadAny,
+		PermOrdersReadOwn,
+		PermOrdersWrite,
+		PermUsersWrite,
+		PermLogsWrite,
+	},
+	RoleManager: {
+		PermCardsWrite,
+		PermStockRead,
+		PermStockWrite,
+		PermOrdersReadAny,
+		PermOrdersReadOwn,
+		PermOrdersWrite,
+	},
+	RoleCustomer: {
+		PermOrdersReadOwn,
+	},
+}
+
+// IsValidRole сообщает, известна ли такая роль.
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission сообщает, выдано ли роли право permission.
+func HasPermission(role, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		permission string
+		want       bool
+	}{
+		{"admin writes cards", RoleAdmin, PermCardsWrite, true},
+		{"admin changes log level", RoleAdmin, PermLogsWrite, true},
+		{"admin manages users", RoleAdmin, PermUsersWrite, true},
+		{"manager writes cards", RoleManager, PermCardsWrite, true},
+		{"manager reads any order", RoleManager, PermOrdersReadAny, true},
+		{"manager cannot edit characteristics", RoleManager, PermCharacteristicsWrite, false},
+		{"manager cannot purge nodes", RoleManager, PermNodesPurge, false},
+		{"manager cannot manage users", RoleManager, PermUsersWrite, false},
+		{"customer reads own orders", RoleCustomer, PermOrdersReadOwn, true},
+		{"customer cannot read any order", RoleCustomer, PermOrdersReadAny, false},
+		{"customer cannot write cards", RoleCustomer, PermCardsWrite, false},
+		{"unknown role", "guest", PermOrdersReadOwn, false},
+		{"empty role", "", PermCardsWrite, false},
+		{"unknown permission", RoleAdmin, "cards:delete", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, HasPermission(tt.role, tt.permission))
+		})
+	}
+}
+
+func TestEveryRoleIsValid(t *testing.T) {
+	for _, role := range []string{RoleAdmin, RoleManager, RoleCustomer} {
+		assert.True(t, IsValidRole(role), role)
+	}
+	assert.False(t, IsValidRole("guest"))
+}
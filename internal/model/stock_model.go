@@ -0,0 +1,20 @@
+package model
+
+type StockRow struct {
+	ID        int    `db:"id" json:"id"`
+	NodeId    int    `db:"node_id" json:"nodeId"`
+	SizeId    int    `db:"size_id" json:"sizeId"`
+	Size      string `db:"size" json:"size"`
+	Quantity  int    `db:"quantity" json:"quantity"`
+	Reserved  int    `db:"reserved" json:"reserved"`
+	Available int    `db:"available" json:"available"`
+}
+
+// UnavailableStockItem описывает позицию заказа, для которой не хватило остатков.
+type UnavailableStockItem struct {
+	Index     int     `json:"index"`
+	NodeId    int     `json:"nodeId"`
+	Size      *string `json:"size"`
+	Requested int     `json:"requested"`
+	Available int     `json:"available"`
+}
@@ -0,0 +1,36 @@
+package model
+
+// Ограничения подсказок поиска.
+const (
+	DefaultSuggestLimit   = 5
+	MaxSuggestLimit       = 10
+	MaxSuggestQueryLength = 100
+)
+
+type TitleSuggestion struct {
+	NodeId int     `db:"nodeId" json:"nodeId"`
+	Title  string  `db:"title" json:"title"`
+	Score  float64 `db:"score" json:"score"`
+}
+
+type NodeTypeSuggestion struct {
+	NodeTypeId int     `db:"nodeTypeId" json:"nodeTypeId"`
+	Type       string  `db:"type" json:"type"`
+	Score      float64 `db:"score" json:"score"`
+}
+
+type CharValueSuggestion struct {
+	CharacteristicId int     `db:"characteristicId" json:"characteristicId"`
+	Characteristic   string  `db:"characteristic" json:"characteristic"`
+	Value            string  `db:"value" json:"value"`
+	Score            float64 `db:"score" json:"score"`
+}
+
+// SuggestResponse — подсказки для строки поиска. Partial означает, что часть подсказок
+// не успела собраться за отведённое время и соответствующие списки пусты.
+type SuggestResponse struct {
+	Titles    []TitleSuggestion     `json:"titles"`
+	NodeTypes []NodeTypeSuggestion  `json:"nodeTypes"`
+	Values    []CharValueSuggestion `json:"values"`
+	Partial   bool                  `json:"partial"`
+}
@@ -0,0 +1,17 @@
+package model
+
+// Роли пользователей.
+const (
+	RoleAdmin    = "admin"
+	RoleManager  = "manager"
+	RoleCustomer = "customer"
+)
+
+type UserRow struct {
+	ID           int    `db:"id" json:"id"`
+	Email        string `db:"email" json:"email"`
+	Phone        string `db:"phone" json:"phone"`
+	PasswordHash string `db:"password_hash" json:"-"`
+	Role         string `db:"role" json:"role"`
+	CreatedAt    string `db:"created_at" json:"createdAt"`
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/lib/pq"
+	"shop/internal/model"
+	"strings"
+)
+
+// Запросы списка карточек: фильтры (WHERE) и сортировки (ORDER BY + keyset-условие для курсора).
+
+// cardOrder описывает сортировку списка карточек. Ключ сортировки всегда дополняется n.id
+// в том же направлении, поэтому порядок стабилен и пригоден для keyset-пагинации.
+type cardOrder struct {
+	// keyExpr — SQL-выражение ключа сортировки. Не должно давать NULL, иначе сравнение кортежей
+	// в keyset-условии ломается, поэтому пустые значения заменяются через COALESCE.
+	keyExpr string
+	// keyType — SQL-тип, к которому приводится значение ключа из курсора.
+	keyType string
+	desc    bool
+}
+
+// orderBy возвращает часть ORDER BY (без ключевого слова).
+func (o cardOrder) orderBy() string {
+	direction := "ASC"
+	if o.desc {
+		direction = "DESC"
+	}
+	return fmt.Sprintf("%s %s, n.id %s", o.keyExpr, direction, direction)
+}
+
+// keysetCondition возвращает условие "строго после позиции курсора" для placeholder'ов ключа и id.
+func (o cardOrder) keysetCondition(keyPlaceholder, idPlaceholder int) string {
+	operator := ">"
+	if o.desc {
+		operator = "<"
+	}
+	return fmt.Sprintf("(%s, n.id) %s ($%d::%s, $%d)", o.keyExpr, operator, keyPlaceholder, o.keyType, idPlaceholder)
+}
+
+// discountExpr — скидка ноды в процентах из характеристики "Скидка"; 0, если скидки нет.
+var discountExpr = fmt.Sprintf(`COALESCE((
+		SELECT %s
+		FROM shop.characteristic_values cv
+		         JOIN shop.characteristics c ON c.id = cv.characteristic_id
+		WHERE cv.node_id = n.id
+		  AND c.title = %s
+		LIMIT 1), 0)`, discountValueExpr, pq.QuoteLiteral(discountCharTitle))
+
+// searchQueryPlaceholder и searchConfigPlaceholder — номера аргументов со строкой поиска и конфигурацией
+// поиска: buildWhereClause всегда добавляет их первыми, поэтому выражение релевантности в ORDER BY
+// может ссылаться на те же аргументы.
+const (
+	searchQueryPlaceholder  = 1
+	searchConfigPlaceholder = 2
+)
+
+// headlineMarkers — разметка найденных слов в подсветке ts_headline. Текст ноды не экранируется,
+// поэтому клиент должен экранировать всё, кроме этих тегов.
+const headlineMarkers = "StartSel=<mark>, StopSel=</mark>"
+
+// descriptionHeadlineOptions — размер фрагментов описания в подсветке.
+const descriptionHeadlineOptions = "MaxFragments=2, MaxWords=30, MinWords=10, FragmentDelimiter=\" … \""
+
+// searchConfigExpr возвращает конфигурацию поиска: переданную в запросе (placeholder с пустой строкой
+// означает "не задана") или конфигурацию типа ноды. Требует JOIN shop.node_types nt.
+func searchConfigExpr(configPlaceholder int) string {
+	return fmt.Sprintf("COALESCE(NULLIF($%d::text, '')::regconfig, nt.search_config)", configPlaceholder)
+}
+
+// searchQueryExpr возвращает tsquery для строки поиска из placeholder'ов строки и конфигурации.
+func searchQueryExpr(queryPlaceholder, configPlaceholder int) string {
+	return fmt.Sprintf("plainto_tsquery(%s, $%d::text)", searchConfigExpr(configPlaceholder), queryPlaceholder)
+}
+
+// searchRankExpr возвращает релевантность ноды строке поиска. Веса частей вектора (A — название,
+// B — тип, C — характеристики, D — описание) учитываются ts_rank_cd по умолчанию.
+func searchRankExpr(queryPlaceholder, configPlaceholder int) string {
+	return fmt.Sprintf("ts_rank_cd(n.search_vector, %s)", searchQueryExpr(queryPlaceholder, configPlaceholder))
+}
+
+// cardSortOrder возвращает описание сортировки по её ключу из белого списка model.CardSort*.
+// Товары без цены при сортировке по цене всегда оказываются в конце списка.
+// rankExpr — выражение релевантности (см. searchRankExpr); relevance без поискового запроса
+// (пустой rankExpr) равносильна newest.
+func cardSortOrder(sort, currency, rankExpr string) cardOrder {
+	switch sort {
+	case model.CardSortRelevance:
+		if rankExpr != "" {
+			return cardOrder{keyExpr: rankExpr, keyType: "real", desc: true}
+		}
+	case model.CardSortPriceAsc:
+		return cardOrder{keyExpr: fmt.Sprintf("COALESCE(%s, 2147483647)", priceColumn(currency)), keyType: "int"}
+	case model.CardSortPriceDesc:
+		return cardOrder{keyExpr: fmt.Sprintf("COALESCE(%s, -1)", priceColumn(currency)), keyType: "int", desc: true}
+	case model.CardSortTitle:
+		return cardOrder{keyExpr: "n.title", keyType: "text"}
+	case model.CardSortDiscount:
+		return cardOrder{keyExpr: discountExpr, keyType: "int", desc: true}
+	}
+	return cardOrder{keyExpr: "n.created_at", keyType: "timestamp", desc: true}
+}
+
+// priceColumn возвращает колонку цены для валюты; по умолчанию BYN.
+func priceColumn(currency string) string {
+	if currency == model.CurrencyRub {
+		return "n.price_rub"
+	}
+	return "n.price_byn"
+}
+
+// filterCurrency возвращает валюту фильтра или пустую строку, если фильтра нет.
+func filterCurrency(filter *model.CardFilter) string {
+	if filter == nil {
+		return ""
+	}
+	return filter.Currency
+}
+
+// filterQuery возвращает строку и конфигурацию поиска фильтра или пустые строки, если фильтра нет.
+func filterQuery(filter *model.CardFilter) (string, string) {
+	if filter == nil {
+		return "", ""
+	}
+	return filter.Query, filter.SearchConfig
+}
+
+// appendCondition добавляет условие к части WHERE, сформированной buildWhereClause.
+func appendCondition(whereClause, condition string) string {
+	if whereClause == "" {
+		return "WHERE " + condition
+	}
+	return whereClause + " AND " + condition
+}
+
+// cardListWhereClause дополняет фильтр условиями, общими для всех выборок по списку карточек:
+// нода должна иметь характеристики и, если не запрошены удалённые, не быть удалённой.
+func cardListWhereClause(filter *model.CardFilter, includeRemoved bool) (string, []interface{}) {
+	whereClause, args := buildWhereClause(filter)
+	// Карточка без характеристик не может быть собрана, поэтому такие ноды в список не попадают
+	whereClause = appendCondition(whereClause,
+		"EXISTS (SELECT 1 FROM shop.characteristic_values cv WHERE cv.node_id = n.id)")
+	if !includeRemoved {
+		whereClause = appendCondition(whereClause, "n.removed_at IS NULL")
+	}
+	return whereClause, args
+}
+
+// numericValueExpr приводит значение характеристики к числу; нечисловые значения дают NULL
+// и поэтому не попадают ни в один диапазон.
+const numericValueExpr = `CASE WHEN cv.value ~ '^\s*-?[0-9]+([.,][0-9]+)?\s*$'
+		THEN replace(trim(cv.value), ',', '.')::numeric END`
+
+// buildWhereClause динамически формирует часть WHERE с placeholder’ами по разобранному фильтру.
+//
+// Условия по характеристикам строятся как EXISTS-подзапросы, по одному на характеристику:
+// значения (и диапазоны) одной характеристики объединяются через OR, разные характеристики — через AND.
+// Характеристика задаётся id, чтобы переименование не ломало сохранённые фильтры.
+// Например, 3=M,L&5=Красный даёт
+//
+//	EXISTS (... cv.characteristic_id = 3 AND cv.value = ANY('{M,L}'))
+//	AND EXISTS (... cv.characteristic_id = 5 AND cv.value = ANY('{Красный}'))
+func buildWhereClause(filter *model.CardFilter) (string, []interface{}) {
+	if filter == nil {
+		return "", nil
+	}
+
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	// Строка и конфигурация поиска должны идти первыми (см. searchQueryPlaceholder)
+	if filter.Query != "" {
+		args = append(args, filter.Query, filter.SearchConfig)
+		conditions = append(conditions, fmt.Sprintf("n.search_vector @@ %s",
+			searchQueryExpr(searchQueryPlaceholder, searchConfigPlaceholder)))
+	}
+	if len(filter.NodeTypeIds) > 0 {
+		args = append(args, pq.Array(filter.NodeTypeIds))
+		conditions = append(conditions, fmt.Sprintf("n.node_type_id = ANY($%d)", len(args)))
+	}
+	if filter.PriceMin != nil {
+		args = append(args, *filter.PriceMin)
+		conditions = append(conditions, fmt.Sprintf("%s >= $%d", priceColumn(filter.Currency), len(args)))
+	}
+	if filter.PriceMax != nil {
+		args = append(args, *filter.PriceMax)
+		conditions = append(conditions, fmt.Sprintf("%s <= $%d", priceColumn(filter.Currency), len(args)))
+	}
+	if filter.Sale != nil {
+		args = append(args, discountCharTitle)
+		saleCondition := fmt.Sprintf(`EXISTS (
+			SELECT 1
+			FROM shop.characteristic_values cv
+			         JOIN shop.characteristics c ON c.id = cv.characteristic_id
+			WHERE cv.node_id = n.id
+			  AND c.title = $%d
+			  AND %s > 0)`, len(args), discountValueExpr)
+		if !*filter.Sale {
+			saleCondition = "NOT " + saleCondition
+		}
+		conditions = append(conditions, saleCondition)
+	}
+
+	for _, charFilter := range filter.Characteristics {
+		args = append(args, charFilter.CharacteristicId)
+		subConditions := []string{fmt.Sprintf("cv.characteristic_id = $%d", len(args))}
+
+		// Варианты значений одной характеристики объединяются через OR
+		var alternatives []string
+		if len(charFilter.Values) > 0 {
+			args = append(args, pq.Array(charFilter.Values))
+			alternatives = append(alternatives, fmt.Sprintf("cv.value = ANY($%d)", len(args)))
+		}
+		for _, valueRange := range charFilter.Ranges {
+			var bounds []string
+			if valueRange.Min != nil {
+				args = append(args, *valueRange.Min)
+				bounds = append(bounds, fmt.Sprintf("%s >= $%d", numericValueExpr, len(args)))
+			}
+			if valueRange.Max != nil {
+				args = append(args, *valueRange.Max)
+				bounds = append(bounds, fmt.Sprintf("%s <= $%d", numericValueExpr, len(args)))
+			}
+			alternatives = append(alternatives, "("+strings.Join(bounds, " AND ")+")")
+		}
+		if len(alternatives) > 0 {
+			subConditions = append(subConditions, "("+strings.Join(alternatives, " OR ")+")")
+		}
+
+		condition := fmt.Sprintf(`EXISTS (
+			SELECT 1
+			FROM shop.characteristic_values cv
+			WHERE cv.node_id = n.id
+			  AND %s)`, strings.Join(subConditions, " AND "))
+		if charFilter.Negate {
+			condition = "NOT " + condition
+		}
+		conditions = append(conditions, condition)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
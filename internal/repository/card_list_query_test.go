@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"shop/internal/model"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+var placeholderPattern = regexp.MustCompile(`\$(\d+)`)
+
+// usedPlaceholders возвращает отсортированные номера placeholder'ов, встречающихся в запросе.
+func usedPlaceholders(query string) []int {
+	seen := make(map[int]struct{})
+	for _, match := range placeholderPattern.FindAllStringSubmatch(query, -1) {
+		n, _ := strconv.Atoi(match[1])
+		seen[n] = struct{}{}
+	}
+
+	result := make([]int, 0, len(seen))
+	for n := range seen {
+		result = append(result, n)
+	}
+	sort.Ints(result)
+	return result
+}
+
+func TestBuildWhereClausePlaceholders(t *testing.T) {
+	floatPtr := func(v float64) *float64 { return &v }
+	intPtr := func(v int) *int { return &v }
+	boolPtr := func(v bool) *bool { return &v }
+
+	tests := []struct {
+		name      string
+		filter    *model.CardFilter
+		args      []interface{}
+		fragments []string
+	}{
+		{
+			name:   "nil filter",
+			filter: nil,
+		},
+		{
+			name:   "empty filter",
+			filter: &model.CardFilter{Currency: model.CurrencyByn},
+		},
+		{
+			name:      "node types and price",
+			filter:    &model.CardFilter{NodeTypeIds: []int{2}, PriceMin: intPtr(100), PriceMax: intPtr(500)},
+			args:      []interface{}{pq.Array([]int{2}), 100, 500},
+			fragments: []string{"n.node_type_id = ANY($1)", "n.price_byn >= $2", "n.price_byn <= $3"},
+		},
+		{
+			name:      "search goes first",
+			filter:    &model.CardFilter{Query: "платье", SearchConfig: "russian", NodeTypeIds: []int{2}},
+			args:      []interface{}{"платье", "russian", pq.Array([]int{2})},
+			fragments: []string{"$1::text", "NULLIF($2::text, '')", "n.node_type_id = ANY($3)"},
+		},
+		{
+			name:      "price in rub",
+			filter:    &model.CardFilter{Currency: model.CurrencyRub, PriceMin: intPtr(100), PriceMax: intPtr(500)},
+			args:      []interface{}{100, 500},
+			fragments: []string{"n.price_rub >= $1", "n.price_rub <= $2"},
+		},
+		{
+			name:      "sale",
+			filter:    &model.CardFilter{Sale: boolPtr(false)},
+			args:      []interface{}{discountCharTitle},
+			fragments: []string{"NOT EXISTS", "c.title = $1", "substring(cv.value from", "::int > 0)"},
+		},
+		{
+			name: "characteristics by id",
+			filter: &model.CardFilter{
+				PriceMin: intPtr(10),
+				Characteristics: []model.CharacteristicFilter{
+					{CharacteristicId: 3, Values: []string{"M", "L"}},
+					{CharacteristicId: 7, Ranges: []model.ValueRange{{Min: floatPtr(40), Max: floatPtr(44)}, {Max: floatPtr(30)}}},
+					{CharacteristicId: 5, Values: []string{"Red"}, Negate: true},
+					{CharacteristicId: 9},
+				},
+			},
+			args: []interface{}{
+				10,
+				3, pq.Array([]string{"M", "L"}),
+				7, 40.0, 44.0, 30.0,
+				5, pq.Array([]string{"Red"}),
+				9,
+			},
+			fragments: []string{
+				"n.price_byn >= $1",
+				"cv.characteristic_id = $2 AND (cv.value = ANY($3))",
+				"cv.characteristic_id = $4",
+				">= $5",
+				"<= $6",
+				"<= $7",
+				"NOT EXISTS",
+				"cv.characteristic_id = $8 AND (cv.value = ANY($9))",
+				"cv.characteristic_id = $10)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := buildWhereClause(tt.filter)
+
+			assert.Equal(t, tt.args, args)
+			for _, fragment := range tt.fragments {
+				assert.Contains(t, where, fragment)
+			}
+			if tt.filter != nil && tt.filter.Sale == nil {
+				// Характеристики сопоставляются по id, а не по названию
+				assert.NotContains(t, where, "c.title")
+			}
+
+			// Каждый аргумент используется, и нет placeholder'ов без аргумента
+			expected := make([]int, 0, len(args))
+			for i := range args {
+				expected = append(expected, i+1)
+			}
+			assert.Equal(t, expected, usedPlaceholders(where))
+
+			if len(args) == 0 {
+				assert.Empty(t, where)
+			}
+		})
+	}
+}
+
+func TestCardSortOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     string
+		currency string
+		rankExpr string
+		orderBy  string
+		keyType  string
+	}{
+		{"newest", model.CardSortNewest, model.CurrencyByn, "", "n.created_at DESC, n.id DESC", "timestamp"},
+		{"unknown falls back to newest", "random", model.CurrencyByn, "", "n.created_at DESC, n.id DESC", "timestamp"},
+		{"price asc in byn", model.CardSortPriceAsc, model.CurrencyByn, "", "COALESCE(n.price_byn, 2147483647) ASC, n.id ASC", "int"},
+		{"price asc in rub", model.CardSortPriceAsc, model.CurrencyRub, "", "COALESCE(n.price_rub, 2147483647) ASC, n.id ASC", "int"},
+		{"price desc in rub", model.CardSortPriceDesc, model.CurrencyRub, "", "COALESCE(n.price_rub, -1) DESC, n.id DESC", "int"},
+		{"price without currency uses byn", model.CardSortPriceDesc, "", "", "COALESCE(n.price_byn, -1) DESC, n.id DESC", "int"},
+		{"title", model.CardSortTitle, model.CurrencyByn, "", "n.title ASC, n.id ASC", "text"},
+		{"relevance with search", model.CardSortRelevance, model.CurrencyByn, "rank", "rank DESC, n.id DESC", "real"},
+		{"relevance without search", model.CardSortRelevance, model.CurrencyByn, "", "n.created_at DESC, n.id DESC", "timestamp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := cardSortOrder(tt.sort, tt.currency, tt.rankExpr)
+
+			assert.Equal(t, tt.orderBy, order.orderBy())
+			assert.Equal(t, tt.keyType, order.keyType)
+		})
+	}
+}
+
+func TestCardOrderKeysetCondition(t *testing.T) {
+	asc := cardSortOrder(model.CardSortTitle, model.CurrencyByn, "")
+	assert.Equal(t, "(n.title, n.id) > ($3::text, $4)", asc.keysetCondition(3, 4))
+
+	desc := cardSortOrder(model.CardSortPriceDesc, model.CurrencyRub, "")
+	assert.Equal(t, "(COALESCE(n.price_rub, -1), n.id) < ($1::int, $2)", desc.keysetCondition(1, 2))
+}
@@ -1,111 +1,44 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"github.com/lib/pq"
 	"go.uber.org/zap"
-	"shop/configs/pg_conf"
 	"shop/internal/api/dto"
 	"shop/internal/model"
 	"shop/pkg/log"
 	"shop/pkg/utils"
-	"strconv"
 	"strings"
 )
 
-type cardRepository struct{}
+type cardRepository struct {
+	db txDB
+}
 
 // CardRepositoryInterface описывает методы, необходимые для работы с "карточками" (nodes).
 type CardRepositoryInterface interface {
-	GetCardById(id int) (*[]model.CardRow, error)
-	GetAllCards(pageNumber, pageSize int, filters *[]model.CardFilter) (*[]model.CardRow, int, error)
-	CreateCard(dto *dto.CreateCardDTO) (int, error)
-	FindByVectorSearch(text string, limit int) (*[]model.CardRow, error)
+	GetCardById(ctx context.Context, id int, includeRemoved bool) (*[]model.CardRow, error)
+	GetAllCards(ctx context.Context, pageNumber, pageSize int, cursor *model.CardCursor, filter *model.CardFilter, sort string, includeRemoved bool) (*[]model.CardRow, int, *model.CardCursor, error)
+	CountCards(ctx context.Context, filter *model.CardFilter, includeRemoved bool) (int, error)
+	GetNodeTypeFacets(ctx context.Context, filter *model.CardFilter, includeRemoved bool) ([]model.NodeTypeFacet, error)
+	GetPriceFacets(ctx context.Context, filter *model.CardFilter, includeRemoved bool, step int) ([]model.PriceBucketFacet, error)
+	CreateCard(ctx context.Context, dto *dto.CreateCardDTO) (int, error)
+	UpdateCard(ctx context.Context, id int, dto *dto.UpdateCardDTO) error
+	PatchCard(ctx context.Context, id int, dto *dto.PatchCardDTO) error
 }
 
 // NewCardRepository создаёт новый экземпляр репозитория для работы с карточками.
-func NewCardRepository() CardRepositoryInterface {
-	return &cardRepository{}
-}
-
-var CardRepo = NewCardRepository()
-
-func (r *cardRepository) FindByVectorSearch(text string, limit int) (*[]model.CardRow, error) {
-	if limit < 1 || limit > 20 {
-		limit = 10
-	}
-
-	query := `
-        SELECT
-            n.id           AS "nodeId",
-            n.title,
-            n.description  AS "nodeDescription",
-            n.created_at   AS "createdAt",
-            n.updated_at   AS "updatedAt",
-            n.removed_at   AS "removedAt",
-            n.price_byn AS "priceByn",
-            n.price_rub AS "priceRub",
-            COALESCE(string_to_array(n.images, ','), '{}') AS "images",
-            nt.type        AS "nodeType",
-            nt.description AS "nodeTypeDescription",
-            c.title        AS "characteristic",
-            cv.value       AS "characteristicValue",
-            cv.add_params  AS "additionalParams",
-            c.description  AS "characteristicDescription"
-        FROM shop.nodes n
-                 JOIN shop.node_types nt ON nt.id = n.node_type_id
-                 JOIN shop.characteristic_values cv ON n.id = cv.node_id
-                 JOIN shop.characteristics c ON c.id = cv.characteristic_id
-        WHERE n.search_vector @@ plainto_tsquery('russian', $1)
-        ORDER BY ts_rank_cd(n.search_vector, plainto_tsquery('russian', $1)) DESC
-        LIMIT $2
-    `
-
-	rows, err := pg_conf.GetDB().Query(query, text, limit)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var cards []model.CardRow
-	for rows.Next() {
-		var card model.CardRow
-		if err := rows.Scan(
-			&card.NodeId,
-			&card.Title,
-			&card.NodeDescription,
-			&card.CreatedAt,
-			&card.UpdatedAt,
-			&card.RemovedAt,
-			&card.PriceByn,
-			&card.PriceRub,
-			pq.Array(&card.Images),
-			&card.NodeType,
-			&card.NodeTypeDescription,
-			&card.Characteristic,
-			&card.CharacteristicValue,
-			&card.AdditionalParams,
-			&card.CharacteristicDescription,
-		); err != nil {
-			log.Error("Failed to scan row in FindByVectorSearch", zap.Error(err))
-			return nil, err
-		}
-		cards = append(cards, card)
-	}
-
-	if err := rows.Err(); err != nil {
-		log.Error("Row iteration error in FindByVectorSearch", zap.Error(err))
-		return nil, err
-	}
-
-	return &cards, nil
+func NewCardRepository(db *sql.DB) CardRepositoryInterface {
+	return &cardRepository{db: txDB{db: db}}
 }
 
 // GetCardById возвращает список характеристик (CardRow) для заданного nodeId.
-func (r *cardRepository) GetCardById(id int) (*[]model.CardRow, error) {
+// Удалённая нода возвращается только при includeRemoved.
+func (r *cardRepository) GetCardById(ctx context.Context, id int, includeRemoved bool) (*[]model.CardRow, error) {
 	// Выполняем запрос к базе данных
-	rows, err := pg_conf.GetDB().Query(
+	rows, err := r.db.QueryContext(ctx,
 		`
         SELECT n.id           AS "nodeId",
                n.title,
@@ -127,8 +60,10 @@ func (r *cardRepository) GetCardById(id int) (*[]model.CardRow, error) {
                  JOIN shop.characteristic_values cv ON n.id = cv.node_id
                  JOIN shop.characteristics c ON c.id = cv.characteristic_id
         WHERE n.id = $1
+          AND ($2 OR n.removed_at IS NULL)
         `,
 		id,
+		includeRemoved,
 	)
 	if err != nil {
 		return nil, err
@@ -157,7 +92,7 @@ func (r *cardRepository) GetCardById(id int) (*[]model.CardRow, error) {
 			&card.AdditionalParams,
 			&card.CharacteristicDescription,
 		); err != nil {
-			log.Error("Failed to scan row", zap.Error(err))
+			log.FromContext(ctx).Error("Failed to scan row", zap.Error(err))
 			return nil, err
 		}
 		cards = append(cards, card)
@@ -165,14 +100,27 @@ func (r *cardRepository) GetCardById(id int) (*[]model.CardRow, error) {
 
 	// Проверяем ошибки, возникшие при итерации
 	if err := rows.Err(); err != nil {
-		log.Error("Error during rows iteration", zap.Error(err))
+		log.FromContext(ctx).Error("Error during rows iteration", zap.Error(err))
 		return nil, err
 	}
 
 	return &cards, nil
 }
 
-func (r *cardRepository) GetAllCards(pageNumber, pageSize int, filters *[]model.CardFilter) (*[]model.CardRow, int, error) {
+// GetAllCards возвращает страницу карточек. Пагинация применяется к уникальным id нод,
+// а характеристики выбираются отдельным запросом, поэтому карточки на странице всегда полные.
+//
+// Если cursor != nil, используется keyset-пагинация по (ключ сортировки, id) и pageNumber игнорируется,
+// иначе — LIMIT/OFFSET по pageNumber. Возвращает курсор следующей страницы (nil, если страница последняя).
+// Удалённые ноды попадают в выборку только при includeRemoved.
+func (r *cardRepository) GetAllCards(
+	ctx context.Context,
+	pageNumber, pageSize int,
+	cursor *model.CardCursor,
+	filter *model.CardFilter,
+	sort string,
+	includeRemoved bool,
+) (*[]model.CardRow, int, *model.CardCursor, error) {
 	// Установка значений по умолчанию для пагинации
 	if pageNumber < 1 {
 		pageNumber = 1
@@ -181,42 +129,109 @@ func (r *cardRepository) GetAllCards(pageNumber, pageSize int, filters *[]model.
 		pageSize = 10
 	}
 
-	offset := utils.CalculateOffset(pageNumber, pageSize)
-
 	// Генерируем часть WHERE на основе фильтров и получаем аргументы.
-	whereClause, whereArgs := buildWhereClause(*filters)
+	whereClause, whereArgs := cardListWhereClause(filter, includeRemoved)
 
 	// -----------------------------------------------------------
 	// Считаем количество с учётом фильтров
 	// -----------------------------------------------------------
-	countQuery := fmt.Sprintf(`
-		SELECT COUNT(*)
-		FROM (
-			SELECT n.id
-			FROM shop.nodes n
-			         JOIN shop.node_types nt ON nt.id = n.node_type_id
-			         JOIN shop.characteristic_values cv ON n.id = cv.node_id
-			         JOIN shop.characteristics c ON c.id = cv.characteristic_id
-			%s
-			GROUP BY n.id
-		) grouped_nodes;
-	`, whereClause)
-
-	var totalCount int
-	if err := pg_conf.GetDB().QueryRow(countQuery, whereArgs...).Scan(&totalCount); err != nil {
-		log.Error("Failed to count cards with filters", zap.Error(err))
-		return nil, 0, err
+	totalCount, err := r.CountCards(ctx, filter, includeRemoved)
+	if err != nil {
+		return nil, 0, nil, err
 	}
 
 	// -----------------------------------------------------------
-	// Формируем основной SELECT с пагинацией
+	// 1. Выбираем id нод страницы (на одну больше, чтобы понять, есть ли следующая)
 	// -----------------------------------------------------------
-	args := make([]interface{}, 0, len(whereArgs)+2)
+	query, searchConfig := filterQuery(filter)
+
+	rankExpr := ""
+	if query != "" {
+		rankExpr = searchRankExpr(searchQueryPlaceholder, searchConfigPlaceholder)
+	}
+	order := cardSortOrder(sort, filterCurrency(filter), rankExpr)
+
+	args := make([]interface{}, 0, len(whereArgs)+4)
 	args = append(args, whereArgs...)
-	args = append(args, pageSize)
-	args = append(args, offset)
 
-	selectQuery := fmt.Sprintf(`
+	offset := 0
+	if cursor != nil {
+		args = append(args, cursor.Key, cursor.ID)
+		whereClause = appendCondition(whereClause, order.keysetCondition(len(args)-1, len(args)))
+	} else {
+		offset = utils.CalculateOffset(pageNumber, pageSize)
+	}
+	args = append(args, pageSize+1, offset)
+
+	idsQuery := fmt.Sprintf(`
+		SELECT n.id, (%s)::text
+		FROM shop.nodes n
+		         JOIN shop.node_types nt ON nt.id = n.node_type_id
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`,
+		order.keyExpr,
+		whereClause, // подставляем строку WHERE (может быть пустой, если фильтров нет)
+		order.orderBy(),
+		len(args)-1, // placeholder для LIMIT
+		len(args),   // placeholder для OFFSET
+	)
+
+	idRows, err := r.db.QueryContext(ctx, idsQuery, args...)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to fetch card ids", zap.Error(err))
+		return nil, 0, nil, err
+	}
+	defer func() {
+		if closeErr := idRows.Close(); closeErr != nil {
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	currency := filterCurrency(filter)
+	positions, err := utils.DecodeRows[model.CardCursor](idRows, func(rows *sql.Rows) (model.CardCursor, error) {
+		position := model.CardCursor{Sort: sort, Currency: currency}
+		err := rows.Scan(&position.ID, &position.Key)
+		return position, err
+	})
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to decode card ids", zap.Error(err))
+		return nil, 0, nil, err
+	}
+
+	var nextCursor *model.CardCursor
+	if len(positions) > pageSize {
+		positions = positions[:pageSize]
+		last := positions[pageSize-1]
+		nextCursor = &last
+	}
+
+	cards := make([]model.CardRow, 0, pageSize)
+	if len(positions) == 0 {
+		return &cards, totalCount, nil, nil
+	}
+
+	ids := make([]int64, 0, len(positions))
+	for _, position := range positions {
+		ids = append(ids, int64(position.ID))
+	}
+
+	// -----------------------------------------------------------
+	// 2. Выбираем все характеристики нод страницы в порядке id из первого запроса.
+	// При поиске для каждой ноды один раз считаются релевантность и подсветка (CTE search).
+	// -----------------------------------------------------------
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+        WITH search AS (
+            SELECT n.id,
+                   %[1]s AS rank,
+                   ts_headline(%[2]s, n.title, %[3]s, '%[4]s, HighlightAll=true') AS title,
+                   ts_headline(%[2]s, n.description, %[3]s, '%[4]s, %[5]s') AS description
+            FROM shop.nodes n
+                     JOIN shop.node_types nt ON nt.id = n.node_type_id
+            WHERE n.id = ANY($1)
+              AND $2::text <> ''
+        )
         SELECT n.id           AS "nodeId",
                n.title,
                n.description  AS "nodeDescription",
@@ -231,30 +246,30 @@ func (r *cardRepository) GetAllCards(pageNumber, pageSize int, filters *[]model.
                c.title        AS "characteristic",
                cv.value       AS "characteristicValue",
                cv.add_params  AS "additionalParams",
-               c.description  AS "characteristicDescription"
+               c.description  AS "characteristicDescription",
+               s.rank         AS "rank",
+               s.title        AS "titleHighlight",
+               s.description  AS "descriptionHighlight"
         FROM shop.nodes n
                  JOIN shop.node_types nt ON nt.id = n.node_type_id
                  JOIN shop.characteristic_values cv ON n.id = cv.node_id
                  JOIN shop.characteristics c ON c.id = cv.characteristic_id
-        %s
-        ORDER BY n.created_at DESC
-        LIMIT $%d OFFSET $%d
+                 LEFT JOIN search s ON s.id = n.id
+        WHERE n.id = ANY($1)
+        ORDER BY array_position($1, n.id::bigint), c.id, cv.value
     `,
-		whereClause,      // подставляем строку WHERE (может быть пустой, если фильтров нет)
-		len(whereArgs)+1, // placeholder для LIMIT
-		len(whereArgs)+2, // placeholder для OFFSET
-	)
-
-	log.Info("Executing select query", zap.String("query", selectQuery), zap.Any("params", args))
-
-	rows, err := pg_conf.GetDB().Query(selectQuery, args...)
+		searchRankExpr(2, 3),
+		searchConfigExpr(3),
+		searchQueryExpr(2, 3),
+		headlineMarkers,
+		descriptionHeadlineOptions,
+	), pq.Array(ids), query, searchConfig)
 	if err != nil {
-		log.Error("Failed to fetch cards", zap.Error(err))
-		return nil, 0, err
+		log.FromContext(ctx).Error("Failed to fetch cards", zap.Error(err))
+		return nil, 0, nil, err
 	}
 	defer rows.Close()
 
-	cards := make([]model.CardRow, 0, pageSize)
 	for rows.Next() {
 		var card model.CardRow
 		if err := rows.Scan(
@@ -273,124 +288,160 @@ func (r *cardRepository) GetAllCards(pageNumber, pageSize int, filters *[]model.
 			&card.CharacteristicValue,
 			&card.AdditionalParams,
 			&card.CharacteristicDescription,
+			&card.Rank,
+			&card.TitleHighlight,
+			&card.DescriptionHighlight,
 		); err != nil {
-			log.Error("Failed to scan row", zap.Error(err))
-			return nil, 0, err
+			log.FromContext(ctx).Error("Failed to scan row", zap.Error(err))
+			return nil, 0, nil, err
 		}
 		cards = append(cards, card)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Error("Error during rows iteration", zap.Error(err))
-		return nil, 0, err
+		log.FromContext(ctx).Error("Error during rows iteration", zap.Error(err))
+		return nil, 0, nil, err
 	}
 
-	return &cards, totalCount, nil
+	return &cards, totalCount, nextCursor, nil
 }
 
-// buildWhereClause динамически формирует часть WHERE с placeholder’ами.
-// Пример фильтров:
-//
-//	[
-//	    { Key: "Размеры",   Values: "M" },
-//	    { Key: "Скидка",    Values: "" },
-//	    { Key: "nodeType",  Values: 1 } // значение виде int
-//	]
-//
-// Если присутствует фильтр nodeType, то условие будет вида (nt.id = $X)
-func buildWhereClause(filters []model.CardFilter) (string, []interface{}) {
-	if len(filters) == 0 {
-		return "", nil
+// CountCards возвращает количество карточек, подходящих под фильтр.
+func (r *cardRepository) CountCards(ctx context.Context, filter *model.CardFilter, includeRemoved bool) (int, error) {
+	whereClause, args := cardListWhereClause(filter, includeRemoved)
+
+	query := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM shop.nodes n
+		         JOIN shop.node_types nt ON nt.id = n.node_type_id
+		%s
+	`, whereClause)
+
+	var totalCount int
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&totalCount); err != nil {
+		log.FromContext(ctx).Error("Failed to count cards with filters", zap.Error(err))
+		return 0, err
 	}
 
-	var (
-		conditions       []string
-		args             []interface{}
-		placeholderIndex = 1
-	)
+	return totalCount, nil
+}
 
-	for _, f := range filters {
-		// Обработка специального фильтра nodeType
-		if f.Key == "nodeTypeId" {
-			// Приводим значение к int. Если конвертация не удалась, фильтр пропускается.
-			nodeTypeID, err := strconv.Atoi(f.Values)
-			if err != nil {
-				log.Warn("Invalid nodeType filter value, skipping nodeType filter", zap.String("value", f.Values))
-				continue
-			}
-			conditions = append(conditions, fmt.Sprintf("(nt.id = $%d)", placeholderIndex))
-			args = append(args, nodeTypeID)
-			placeholderIndex++
-			continue
+// GetNodeTypeFacets возвращает количество подходящих под фильтр карточек по каждому типу нод.
+func (r *cardRepository) GetNodeTypeFacets(ctx context.Context, filter *model.CardFilter, includeRemoved bool) ([]model.NodeTypeFacet, error) {
+	whereClause, args := cardListWhereClause(filter, includeRemoved)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT nt.id, nt.type, COUNT(*)
+		FROM shop.nodes n
+		         JOIN shop.node_types nt ON nt.id = n.node_type_id
+		%s
+		GROUP BY nt.id, nt.type
+		ORDER BY nt.id
+	`, whereClause), args...)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to fetch node type facets", zap.Error(err))
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
 		}
+	}()
 
-		// Остальные фильтры
-		if f.Values == "" {
-			// Условие вида (c.title = $X)
-			conditions = append(conditions, fmt.Sprintf("(c.title = $%d)", placeholderIndex))
-			args = append(args, f.Key)
-			placeholderIndex++
-		} else {
-			// Условие вида (c.title = $X OR cv.value = $%d)
-			conditions = append(conditions, fmt.Sprintf("(c.title = $%d OR cv.value = $%d)", placeholderIndex, placeholderIndex+1))
-			args = append(args, f.Key, f.Values)
-			placeholderIndex += 2
-		}
+	facets, err := utils.DecodeRows[model.NodeTypeFacet](rows, func(rows *sql.Rows) (model.NodeTypeFacet, error) {
+		var facet model.NodeTypeFacet
+		err := rows.Scan(&facet.NodeTypeId, &facet.Type, &facet.Count)
+		return facet, err
+	})
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to decode node type facets", zap.Error(err))
+		return nil, err
 	}
 
-	if len(conditions) == 0 {
-		return "", nil
+	if facets == nil {
+		facets = make([]model.NodeTypeFacet, 0)
 	}
 
-	whereClause := "WHERE " + strings.Join(conditions, " AND ")
-	return whereClause, args
+	return facets, nil
 }
 
-// CreateCard реализует логику создания node и его характеристик.
-func (r *cardRepository) CreateCard(dto *dto.CreateCardDTO) (int, error) {
-	// Начинаем транзакцию
-	tx, err := pg_conf.GetDB().Begin()
+// GetPriceFacets разбивает цены подходящих под фильтр карточек на интервалы шириной step
+// в валюте фильтра. Возвращаются только непустые интервалы; карточки без цены не учитываются.
+func (r *cardRepository) GetPriceFacets(ctx context.Context, filter *model.CardFilter, includeRemoved bool, step int) ([]model.PriceBucketFacet, error) {
+	whereClause, args := cardListWhereClause(filter, includeRemoved)
+	price := priceColumn(filterCurrency(filter))
+	whereClause = appendCondition(whereClause, price+" IS NOT NULL")
+
+	args = append(args, step)
+	bucketExpr := fmt.Sprintf("(%s / $%d) * $%d", price, len(args), len(args))
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s AS bucket, COUNT(*)
+		FROM shop.nodes n
+		         JOIN shop.node_types nt ON nt.id = n.node_type_id
+		%s
+		GROUP BY bucket
+		ORDER BY bucket
+	`, bucketExpr, whereClause), args...)
 	if err != nil {
-		log.Error("Failed to begin transaction", zap.Error(err))
-		return 0, err
+		log.FromContext(ctx).Error("Failed to fetch price facets", zap.Error(err))
+		return nil, err
 	}
-
 	defer func() {
-		// Если случится паника — откатываемся
-		if p := recover(); p != nil {
-			_ = tx.Rollback()
-			panic(p)
+		if closeErr := rows.Close(); closeErr != nil {
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
 		}
 	}()
 
-	// 1. Вставляем запись в shop.nodes
-	newNodeID, err := r.insertNodeTx(tx, dto)
+	facets, err := utils.DecodeRows[model.PriceBucketFacet](rows, func(rows *sql.Rows) (model.PriceBucketFacet, error) {
+		var facet model.PriceBucketFacet
+		if err := rows.Scan(&facet.Min, &facet.Count); err != nil {
+			return model.PriceBucketFacet{}, err
+		}
+		facet.Max = facet.Min + step - 1
+		return facet, nil
+	})
 	if err != nil {
-		_ = tx.Rollback()
-		log.Error("Failed to insert node", zap.Error(err))
-		return 0, err
+		log.FromContext(ctx).Error("Failed to decode price facets", zap.Error(err))
+		return nil, err
 	}
 
-	// 2. Вставляем все характеристики (bulk insert)
-	err = r.insertCharacteristicsTx(tx, newNodeID, dto.Characteristics)
-	if err != nil {
-		_ = tx.Rollback()
-		log.Error("Failed to insert characteristics", zap.Error(err))
-		return 0, err
+	if facets == nil {
+		facets = make([]model.PriceBucketFacet, 0)
 	}
 
-	// 3. Коммитим транзакцию
-	if err := tx.Commit(); err != nil {
-		log.Error("Failed to commit transaction", zap.Error(err))
+	return facets, nil
+}
+
+// CreateCard реализует логику создания node и его характеристик.
+func (r *cardRepository) CreateCard(ctx context.Context, dto *dto.CreateCardDTO) (int, error) {
+	var newNodeID int
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		// 1. Вставляем запись в shop.nodes
+		var err error
+		newNodeID, err = r.insertNodeTx(ctx, dto)
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to insert node", zap.Error(err))
+			return err
+		}
+
+		// 2. Вставляем все характеристики (bulk insert)
+		if err := r.insertCharacteristicsTx(ctx, newNodeID, dto.Characteristics); err != nil {
+			log.FromContext(ctx).Error("Failed to insert characteristics", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
 		return 0, err
 	}
 
-	log.Info(fmt.Sprintf("Card successfully created with ID: %d", newNodeID))
+	log.FromContext(ctx).Info(fmt.Sprintf("Card successfully created with ID: %d", newNodeID))
 	return newNodeID, nil
 }
 
 // insertNodeTx — вспомогательная функция, вставляет запись в shop.nodes.
-func (r *cardRepository) insertNodeTx(tx *sql.Tx, dto *dto.CreateCardDTO) (int, error) {
+func (r *cardRepository) insertNodeTx(ctx context.Context, dto *dto.CreateCardDTO) (int, error) {
 	// Преобразуем массив изображений в строку через запятую
 	imagesString := strings.Join(dto.Images, ",")
 	const query = `
@@ -400,7 +451,7 @@ func (r *cardRepository) insertNodeTx(tx *sql.Tx, dto *dto.CreateCardDTO) (int,
 	`
 
 	var newNodeID int
-	err := tx.QueryRow(query, dto.Title, dto.NodeDescription, dto.NodeTypeId, imagesString).Scan(&newNodeID)
+	err := r.db.QueryRowContext(ctx, query, dto.Title, dto.NodeDescription, dto.NodeTypeId, imagesString).Scan(&newNodeID)
 	if err != nil {
 		return 0, err
 	}
@@ -409,7 +460,7 @@ func (r *cardRepository) insertNodeTx(tx *sql.Tx, dto *dto.CreateCardDTO) (int,
 
 // insertCharacteristicsTx — вспомогательная функция, вставляет записи в shop.characteristic_values (bulk insert).
 func (r *cardRepository) insertCharacteristicsTx(
-	tx *sql.Tx,
+	ctx context.Context,
 	nodeID int,
 	characteristics []dto.CharDTO,
 ) error {
@@ -454,6 +505,222 @@ func (r *cardRepository) insertCharacteristicsTx(
 
 	// Склеиваем плейсхолдеры в один INSERT
 	query := baseQuery + strings.Join(valuesPlaceholder, ",")
-	_, err := tx.Exec(query, args...)
+	_, err := r.db.ExecContext(ctx, query, args...)
 	return err
 }
+
+// UpdateCard полностью заменяет поля ноды и весь набор её характеристик в одной транзакции.
+func (r *cardRepository) UpdateCard(ctx context.Context, id int, dto *dto.UpdateCardDTO) error {
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		// 1. Обновляем запись в shop.nodes
+		_, err := r.db.ExecContext(ctx, `
+			UPDATE shop.nodes
+			SET title        = $1,
+			    description  = $2,
+			    node_type_id = $3,
+			    price_byn    = $4,
+			    price_rub    = $5,
+			    images       = $6
+			WHERE id = $7`,
+			dto.Title, dto.NodeDescription, dto.NodeTypeId, dto.PriceByn, dto.PriceRub, strings.Join(dto.Images, ","), id,
+		)
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to update node", zap.Int("id", id), zap.Error(err))
+			return err
+		}
+
+		// 2. Приводим характеристики к переданному набору
+		if err := r.syncCharacteristicsTx(ctx, id, dto.Characteristics, false); err != nil {
+			log.FromContext(ctx).Error("Failed to sync characteristics", zap.Int("id", id), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Info("Card successfully updated", zap.Int("id", id))
+	return nil
+}
+
+// PatchCard обновляет только переданные поля ноды. Если переданы характеристики,
+// заменяются значения только тех характеристик, id которых присутствуют в запросе.
+func (r *cardRepository) PatchCard(ctx context.Context, id int, dto *dto.PatchCardDTO) error {
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		// 1. Обновляем только переданные поля shop.nodes
+		setClause, args := buildPatchSetClause(dto)
+		if setClause != "" {
+			args = append(args, id)
+			query := fmt.Sprintf("UPDATE shop.nodes SET %s WHERE id = $%d", setClause, len(args))
+			if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+				log.FromContext(ctx).Error("Failed to patch node", zap.Int("id", id), zap.Error(err))
+				return err
+			}
+		}
+
+		// 2. Обновляем значения упомянутых характеристик
+		if dto.Characteristics != nil {
+			if err := r.syncCharacteristicsTx(ctx, id, *dto.Characteristics, true); err != nil {
+				log.FromContext(ctx).Error("Failed to sync characteristics", zap.Int("id", id), zap.Error(err))
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Info("Card successfully patched", zap.Int("id", id))
+	return nil
+}
+
+// buildPatchSetClause формирует часть SET с placeholder'ами только для переданных полей.
+func buildPatchSetClause(dto *dto.PatchCardDTO) (string, []interface{}) {
+	var (
+		assignments []string
+		args        []interface{}
+	)
+
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if dto.Title != nil {
+		set("title", *dto.Title)
+	}
+	if dto.NodeDescription != nil {
+		set("description", *dto.NodeDescription)
+	}
+	if dto.NodeTypeId != nil {
+		set("node_type_id", *dto.NodeTypeId)
+	}
+	if dto.PriceByn != nil {
+		set("price_byn", *dto.PriceByn)
+	}
+	if dto.PriceRub != nil {
+		set("price_rub", *dto.PriceRub)
+	}
+	if dto.Images != nil {
+		set("images", strings.Join(*dto.Images, ","))
+	}
+
+	return strings.Join(assignments, ", "), args
+}
+
+// syncCharacteristicsTx — вспомогательная функция, приводит shop.characteristic_values ноды
+// к переданному набору: удаляет лишние значения, добавляет новые и обновляет add_params у оставшихся.
+// Если onlyListed == true, затрагиваются только характеристики, id которых есть в characteristics.
+func (r *cardRepository) syncCharacteristicsTx(
+	ctx context.Context,
+	nodeID int,
+	characteristics []dto.CharDTO,
+	onlyListed bool,
+) error {
+	type charKey struct {
+		id    int
+		value string
+	}
+
+	// Желаемый набор значений; при дублях побеждает последнее
+	desired := make(map[charKey]dto.CharDTO, len(characteristics))
+	charIds := make([]int, 0, len(characteristics))
+	for _, ch := range characteristics {
+		key := charKey{id: ch.Id, value: ch.Value}
+		if _, exists := desired[key]; !exists {
+			charIds = append(charIds, ch.Id)
+		}
+		desired[key] = ch
+	}
+
+	// 1. Читаем текущие значения (с блокировкой строк до конца транзакции)
+	query := "SELECT characteristic_id, value FROM shop.characteristic_values WHERE node_id = $1"
+	args := []interface{}{nodeID}
+	if onlyListed {
+		query += " AND characteristic_id = ANY($2)"
+		args = append(args, pq.Array(charIds))
+	}
+	query += " FOR UPDATE"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	existing, err := utils.DecodeRows[charKey](rows, func(rows *sql.Rows) (charKey, error) {
+		var key charKey
+		err := rows.Scan(&key.id, &key.value)
+		return key, err
+	})
+	_ = rows.Close()
+	if err != nil {
+		return err
+	}
+
+	// 2. Раскладываем значения на удаляемые, сохраняемые и новые
+	var (
+		deleteIds    []int
+		deleteValues []string
+		kept         = make(map[charKey]struct{}, len(existing))
+	)
+	for _, key := range existing {
+		if _, ok := desired[key]; ok {
+			kept[key] = struct{}{}
+			continue
+		}
+		deleteIds = append(deleteIds, key.id)
+		deleteValues = append(deleteValues, key.value)
+	}
+
+	toInsert := make([]dto.CharDTO, 0, len(desired))
+	for _, ch := range characteristics {
+		key := charKey{id: ch.Id, value: ch.Value}
+		if _, ok := kept[key]; ok {
+			continue
+		}
+		if d, ok := desired[key]; ok {
+			toInsert = append(toInsert, d)
+			// Исключаем повторную вставку дубля
+			delete(desired, key)
+		}
+	}
+
+	// 3. Удаляем значения, которых больше нет
+	if len(deleteIds) > 0 {
+		_, err := r.db.ExecContext(ctx, `
+			DELETE FROM shop.characteristic_values cv
+			USING unnest($2::int[], $3::text[]) AS d(characteristic_id, value)
+			WHERE cv.node_id = $1
+			  AND cv.characteristic_id = d.characteristic_id
+			  AND cv.value = d.value`,
+			nodeID, pq.Array(deleteIds), pq.Array(deleteValues),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 4. Обновляем add_params у сохранившихся значений
+	for key := range kept {
+		var addParamsJSON interface{}
+		if ch := desired[key]; ch.AdditionalParams != nil {
+			addParamsJSON = ch.AdditionalParams
+		}
+		_, err := r.db.ExecContext(ctx, `
+			UPDATE shop.characteristic_values
+			SET add_params = $4
+			WHERE node_id = $1
+			  AND characteristic_id = $2
+			  AND value = $3
+			  AND add_params IS DISTINCT FROM $4::jsonb`,
+			nodeID, key.id, key.value, addParamsJSON,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 5. Вставляем новые значения (bulk insert)
+	return r.insertCharacteristicsTx(ctx, nodeID, toInsert)
+}
@@ -1,34 +1,35 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"go.uber.org/zap"
-	"shop/configs/pg_conf"
 	"shop/internal/api/dto"
 	"shop/internal/model"
+	"shop/pkg/app_error"
 	"shop/pkg/log"
 	"shop/pkg/utils"
 )
 
-type charDefaultValueRepository struct{}
+type charDefaultValueRepository struct {
+	db txDB
+}
 
 type CharDefaultValueInterface interface {
-	GetAllDefaultValues(pageNumber, pageSize int) ([]model.CharDefaultValue, int, error)
-	CreateDefaultValue(size *dto.CreateCharDefValueRequest) (int, error)
-	UpdateDefaultValue(size *dto.UpdateCharDefValueRequest) error
-	DeleteDefaultValueById(id int) error
-	GetDefaultValueById(id int) (*model.CharDefaultValueRow, error)
-	GetFullDefaultValueById(id int) (*[]model.CharDefaultValue, error)
+	GetAllDefaultValues(ctx context.Context, pageNumber, pageSize int) ([]model.CharDefaultValue, int, error)
+	CreateDefaultValue(ctx context.Context, size *dto.CreateCharDefValueRequest) (int, error)
+	UpdateDefaultValue(ctx context.Context, size *dto.UpdateCharDefValueRequest) error
+	DeleteDefaultValueById(ctx context.Context, id int) error
+	GetDefaultValueById(ctx context.Context, id int) (*model.CharDefaultValueRow, error)
+	GetFullDefaultValueById(ctx context.Context, id int) (*[]model.CharDefaultValue, error)
 }
 
-func NewCharDefaultValueRepository() CharDefaultValueInterface {
-	return &charDefaultValueRepository{}
+func NewCharDefaultValueRepository(db *sql.DB) CharDefaultValueInterface {
+	return &charDefaultValueRepository{db: txDB{db: db}}
 }
 
-var CharDefaultValueRepo = NewCharDefaultValueRepository()
-
-func (r *charDefaultValueRepository) GetAllDefaultValues(pageNumber, pageSize int) ([]model.CharDefaultValue, int, error) {
+func (r *charDefaultValueRepository) GetAllDefaultValues(ctx context.Context, pageNumber, pageSize int) ([]model.CharDefaultValue, int, error) {
 	if pageNumber < 1 {
 		pageNumber = 1
 	}
@@ -39,13 +40,13 @@ func (r *charDefaultValueRepository) GetAllDefaultValues(pageNumber, pageSize in
 	offset := utils.CalculateOffset(pageNumber, pageSize)
 
 	var totalCount int
-	err := pg_conf.GetDB().QueryRow("SELECT COUNT(*) FROM shop.char_default_value").Scan(&totalCount)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM shop.char_default_value").Scan(&totalCount)
 	if err != nil {
-		log.Error("Failed to count char_default_value", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to count char_default_value", zap.Error(err))
 		return nil, 0, err
 	}
 
-	rows, err := pg_conf.GetDB().Query(`
+	rows, err := r.db.QueryContext(ctx, `
 			SELECT cdv.id,
 				   cdv.characteristic_id,
 				   cdv.value,
@@ -54,12 +55,12 @@ func (r *charDefaultValueRepository) GetAllDefaultValues(pageNumber, pageSize in
 					 JOIN shop.char_default_value cdv on ch.id = cdv.characteristic_id
 			 ORDER BY cdv.id ASC LIMIT $1 OFFSET $2`, pageSize, offset)
 	if err != nil {
-		log.Error("Failed to fetch char_default_value", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch char_default_value", zap.Error(err))
 		return nil, 0, err
 	}
 	defer func() {
 		if closeErr := rows.Close(); closeErr != nil {
-			log.Warn("Failed to close rows", zap.Error(closeErr))
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
 		}
 	}()
 
@@ -78,24 +79,24 @@ func (r *charDefaultValueRepository) GetAllDefaultValues(pageNumber, pageSize in
 
 	data, err := utils.DecodeRows[model.CharDefaultValue](rows, scanFunc)
 	if err != nil {
-		log.Error("Failed to decode char_default_value", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to decode char_default_value", zap.Error(err))
 		return nil, 0, err
 	}
 
 	return data, totalCount, nil
 }
 
-func (r *charDefaultValueRepository) GetFullDefaultValueById(id int) (*[]model.CharDefaultValue, error) {
-	db := pg_conf.GetDB()
+func (r *charDefaultValueRepository) GetFullDefaultValueById(ctx context.Context, id int) (*[]model.CharDefaultValue, error) {
+	db := r.db
 	query := `
 		SELECT cdv.id, cdv.characteristic_id, cdv.value, ch.title
 		FROM shop.char_default_value cdv
 		JOIN shop.characteristics ch ON ch.id = cdv.characteristic_id
 		WHERE ch.id = $1`
 
-	rows, err := db.Query(query, id)
+	rows, err := db.QueryContext(ctx, query, id)
 	if err != nil {
-		log.Error("Failed to execute query", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to execute query", zap.Error(err))
 		return nil, err
 	}
 	defer rows.Close()
@@ -104,28 +105,28 @@ func (r *charDefaultValueRepository) GetFullDefaultValueById(id int) (*[]model.C
 	for rows.Next() {
 		var result model.CharDefaultValue
 		if err := rows.Scan(&result.ID, &result.CharacteristicId, &result.Value, &result.Title); err != nil {
-			log.Error("Failed to scan row", zap.Error(err))
+			log.FromContext(ctx).Error("Failed to scan row", zap.Error(err))
 			return nil, err
 		}
 		results = append(results, result)
 	}
 
 	if err := rows.Err(); err != nil {
-		log.Error("Error iterating over rows", zap.Error(err))
+		log.FromContext(ctx).Error("Error iterating over rows", zap.Error(err))
 		return nil, err
 	}
 
 	if len(results) == 0 {
-		log.Warn("No char_default_value found", zap.Int("id", id))
-		return nil, errors.New("no records found")
+		log.FromContext(ctx).Warn("No char_default_value found", zap.Int("id", id))
+		return nil, app_error.NotFound("No default values found")
 	}
 
 	return &results, nil
 }
 
-func (r *charDefaultValueRepository) CreateDefaultValue(data *dto.CreateCharDefValueRequest) (int, error) {
+func (r *charDefaultValueRepository) CreateDefaultValue(ctx context.Context, data *dto.CreateCharDefValueRequest) (int, error) {
 	var insertedID int
-	err := pg_conf.GetDB().QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		"INSERT INTO shop.char_default_value (characteristic_id,value) VALUES ($1, $2) RETURNING id",
 		data.CharacteristicId, data.Value,
 	).Scan(&insertedID)
@@ -137,31 +138,31 @@ func (r *charDefaultValueRepository) CreateDefaultValue(data *dto.CreateCharDefV
 	return insertedID, nil
 }
 
-func (r *charDefaultValueRepository) UpdateDefaultValue(data *dto.UpdateCharDefValueRequest) error {
-	_, err := pg_conf.GetDB().Exec(
+func (r *charDefaultValueRepository) UpdateDefaultValue(ctx context.Context, data *dto.UpdateCharDefValueRequest) error {
+	_, err := r.db.ExecContext(ctx,
 		"UPDATE shop.char_default_value SET value = $1 WHERE id = $2",
 		data.Value, data.ID,
 	)
 	return err
 }
 
-func (r *charDefaultValueRepository) DeleteDefaultValueById(id int) error {
-	_, err := pg_conf.GetDB().Exec(
+func (r *charDefaultValueRepository) DeleteDefaultValueById(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx,
 		"DELETE FROM shop.char_default_value  WHERE id = $1",
 		id,
 	)
 	if err != nil {
 		// Log the error with context for easier debugging
-		log.Error("Failed to delete node", zap.Int("id", id), zap.Error(err))
+		log.FromContext(ctx).Error("Failed to delete node", zap.Int("id", id), zap.Error(err))
 		return err
 	}
 	return nil
 }
 
-func (r *charDefaultValueRepository) GetDefaultValueById(id int) (*model.CharDefaultValueRow, error) {
+func (r *charDefaultValueRepository) GetDefaultValueById(ctx context.Context, id int) (*model.CharDefaultValueRow, error) {
 	var data model.CharDefaultValueRow
 
-	err := pg_conf.GetDB().QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		"SELECT * FROM shop.char_default_value WHERE id = $1",
 		id,
 	).Scan(
@@ -172,10 +173,10 @@ func (r *charDefaultValueRepository) GetDefaultValueById(id int) (*model.CharDef
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			log.Warn("char_default_value not found", zap.Int("id", id))
-			return nil, errors.New("node not found")
+			log.FromContext(ctx).Warn("char_default_value not found", zap.Int("id", id))
+			return nil, app_error.NotFound("Default value not found")
 		}
-		log.Error("Failed to fetch char_default_value by ID", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch char_default_value by ID", zap.Error(err))
 		return nil, err
 	}
 
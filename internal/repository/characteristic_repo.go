@@ -1,37 +1,39 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/lib/pq"
 	"go.uber.org/zap"
-	"shop/configs/pg_conf"
 	"shop/internal/api/dto"
 	"shop/internal/model"
+	"shop/pkg/app_error"
 	"shop/pkg/log"
 	"shop/pkg/utils"
 )
 
-type characteristicRepository struct{}
+type characteristicRepository struct {
+	db txDB
+}
 
 type CharacteristicRepositoryInterface interface {
-	GetAllCharacteristics(pageNumber, pageSize int) ([]model.CharacteristicRow, int, error)
-	CreateCharacteristics(size *dto.CreateCharacteristicRequest) (int, error)
-	UpdateCharacteristics(size *model.CharacteristicRow) error
-	DeleteCharacteristicsById(id int) error
-	GetCharacteristicsById(id int) (*model.CharacteristicRow, error)
-	CheckCharsByIds(ids []int) error
-	GetCharFilters(nodeTypeId int) (*[]model.CharFiltersRow, error)
+	GetAllCharacteristics(ctx context.Context, pageNumber, pageSize int) ([]model.CharacteristicRow, int, error)
+	CreateCharacteristics(ctx context.Context, size *dto.CreateCharacteristicRequest) (int, error)
+	UpdateCharacteristics(ctx context.Context, size *model.CharacteristicRow) error
+	DeleteCharacteristicsById(ctx context.Context, id int) error
+	GetCharacteristicsById(ctx context.Context, id int) (*model.CharacteristicRow, error)
+	CheckCharsByIds(ctx context.Context, ids []int) error
+	GetCharFilters(ctx context.Context, nodeTypeId int, includeRemoved bool) (*[]model.CharFiltersRow, error)
+	GetCharValueCounts(ctx context.Context, filter *model.CardFilter, includeRemoved bool, characteristicId int) ([]model.CharValueCountRow, error)
 }
 
-func NewCharacteristicRepository() CharacteristicRepositoryInterface {
-	return &characteristicRepository{}
+func NewCharacteristicRepository(db *sql.DB) CharacteristicRepositoryInterface {
+	return &characteristicRepository{db: txDB{db: db}}
 }
 
-var CharacteristicRepo = NewCharacteristicRepository()
-
-func (r *characteristicRepository) GetAllCharacteristics(pageNumber, pageSize int) ([]model.CharacteristicRow, int, error) {
+func (r *characteristicRepository) GetAllCharacteristics(ctx context.Context, pageNumber, pageSize int) ([]model.CharacteristicRow, int, error) {
 	if pageNumber < 1 {
 		pageNumber = 1
 	}
@@ -42,20 +44,20 @@ func (r *characteristicRepository) GetAllCharacteristics(pageNumber, pageSize in
 	offset := utils.CalculateOffset(pageNumber, pageSize)
 
 	var totalCount int
-	err := pg_conf.GetDB().QueryRow("SELECT COUNT(*) FROM shop.characteristics").Scan(&totalCount)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM shop.characteristics").Scan(&totalCount)
 	if err != nil {
-		log.Error("Failed to count characteristic", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to count characteristic", zap.Error(err))
 		return nil, 0, err
 	}
 
-	rows, err := pg_conf.GetDB().Query("SELECT id, title, description, is_visible FROM shop.characteristics ORDER BY id ASC LIMIT $1 OFFSET $2", pageSize, offset)
+	rows, err := r.db.QueryContext(ctx, "SELECT id, title, description, is_visible FROM shop.characteristics ORDER BY id ASC LIMIT $1 OFFSET $2", pageSize, offset)
 	if err != nil {
-		log.Error("Failed to fetch characteristics", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch characteristics", zap.Error(err))
 		return nil, 0, err
 	}
 	defer func() {
 		if closeErr := rows.Close(); closeErr != nil {
-			log.Warn("Failed to close rows", zap.Error(closeErr))
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
 		}
 	}()
 
@@ -69,7 +71,7 @@ func (r *characteristicRepository) GetAllCharacteristics(pageNumber, pageSize in
 
 	chars, err := utils.DecodeRows[model.CharacteristicRow](rows, scanFunc)
 	if err != nil {
-		log.Error("Failed to decode characteristics", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to decode characteristics", zap.Error(err))
 		return nil, 0, err
 	}
 
@@ -80,9 +82,9 @@ func (r *characteristicRepository) GetAllCharacteristics(pageNumber, pageSize in
 	return chars, totalCount, nil
 }
 
-func (r *characteristicRepository) CreateCharacteristics(data *dto.CreateCharacteristicRequest) (int, error) {
+func (r *characteristicRepository) CreateCharacteristics(ctx context.Context, data *dto.CreateCharacteristicRequest) (int, error) {
 	var insertedID int
-	err := pg_conf.GetDB().QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		"INSERT INTO shop.characteristics (title, description) VALUES ($1, $2) RETURNING id",
 		data.Title, data.Description,
 	).Scan(&insertedID)
@@ -94,47 +96,47 @@ func (r *characteristicRepository) CreateCharacteristics(data *dto.CreateCharact
 	return insertedID, nil
 }
 
-func (r *characteristicRepository) UpdateCharacteristics(data *model.CharacteristicRow) error {
-	_, err := pg_conf.GetDB().Exec(
+func (r *characteristicRepository) UpdateCharacteristics(ctx context.Context, data *model.CharacteristicRow) error {
+	_, err := r.db.ExecContext(ctx,
 		"UPDATE shop.characteristics SET title = $1, description = $2, is_visible = $3 WHERE id = $4",
 		data.Title, data.Description, data.IsVisible, data.ID,
 	)
 	return err
 }
 
-func (r *characteristicRepository) DeleteCharacteristicsById(id int) error {
-	_, err := pg_conf.GetDB().Exec("DELETE FROM shop.characteristics WHERE id = $1", id)
+func (r *characteristicRepository) DeleteCharacteristicsById(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM shop.characteristics WHERE id = $1", id)
 	return err
 }
 
-func (r *characteristicRepository) GetCharacteristicsById(id int) (*model.CharacteristicRow, error) {
+func (r *characteristicRepository) GetCharacteristicsById(ctx context.Context, id int) (*model.CharacteristicRow, error) {
 	var char model.CharacteristicRow
 
-	err := pg_conf.GetDB().QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		"SELECT id, title, description FROM shop.characteristics WHERE id = $1",
 		id,
 	).Scan(&char.ID, &char.Title, &char.Description)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			log.Warn("Title not found", zap.Int("id", id))
-			return nil, errors.New("size not found")
+			log.FromContext(ctx).Warn("Title not found", zap.Int("id", id))
+			return nil, app_error.NotFound("Characteristic not found")
 		}
-		log.Error("Failed to fetch characteristic by ID", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch characteristic by ID", zap.Error(err))
 		return nil, err
 	}
 
 	return &char, nil
 }
 
-func (r *characteristicRepository) CheckCharsByIds(ids []int) error {
+func (r *characteristicRepository) CheckCharsByIds(ctx context.Context, ids []int) error {
 	// Выполняем запрос, чтобы получить все характеристики с указанными id
-	rows, err := pg_conf.GetDB().Query(
+	rows, err := r.db.QueryContext(ctx,
 		"SELECT id FROM shop.characteristics WHERE id = ANY($1)",
 		pq.Array(ids),
 	)
 	if err != nil {
-		log.Error("Failed to fetch characteristics by IDs", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch characteristics by IDs", zap.Error(err))
 		return err
 	}
 	defer rows.Close()
@@ -144,7 +146,7 @@ func (r *characteristicRepository) CheckCharsByIds(ids []int) error {
 	for rows.Next() {
 		var id int
 		if err := rows.Scan(&id); err != nil {
-			log.Error("Failed to scan characteristic row", zap.Error(err))
+			log.FromContext(ctx).Error("Failed to scan characteristic row", zap.Error(err))
 			return err
 		}
 		foundIDs[id] = struct{}{}
@@ -152,7 +154,7 @@ func (r *characteristicRepository) CheckCharsByIds(ids []int) error {
 
 	// Проверяем наличие ошибок при обработке строк
 	if err := rows.Err(); err != nil {
-		log.Error("Error occurred during rows iteration", zap.Error(err))
+		log.FromContext(ctx).Error("Error occurred during rows iteration", zap.Error(err))
 		return err
 	}
 
@@ -166,14 +168,16 @@ func (r *characteristicRepository) CheckCharsByIds(ids []int) error {
 
 	// Если есть отсутствующие ID, возвращаем ошибку
 	if len(missingIDs) > 0 {
-		log.Warn("Some IDs are missing", zap.Ints("missingIDs", missingIDs))
-		return fmt.Errorf("these IDs don't  exist: %v", missingIDs)
+		log.FromContext(ctx).Warn("Some IDs are missing", zap.Ints("missingIDs", missingIDs))
+		return app_error.Validation("these IDs don't exist: %v", missingIDs)
 	}
 
 	return nil
 }
 
-func (r *characteristicRepository) GetCharFilters(nodeTypeId int) (*[]model.CharFiltersRow, error) {
+// GetCharFilters возвращает значения характеристик для фильтров. Значения, встречающиеся только
+// у удалённых нод, учитываются лишь при includeRemoved.
+func (r *characteristicRepository) GetCharFilters(ctx context.Context, nodeTypeId int, includeRemoved bool) (*[]model.CharFiltersRow, error) {
 	// Базовая часть запроса (без условия по nodeTypeId)
 	baseQuery := `
 		SELECT DISTINCT ch.id AS characteristicId,
@@ -188,6 +192,7 @@ func (r *characteristicRepository) GetCharFilters(nodeTypeId int) (*[]model.Char
 		         JOIN shop.nodes AS n
 		           ON n.id = cv.node_id
 		WHERE ch.is_visible = true
+		  AND ($1 OR n.removed_at IS NULL)
 	`
 
 	var (
@@ -200,22 +205,22 @@ func (r *characteristicRepository) GetCharFilters(nodeTypeId int) (*[]model.Char
 		query := baseQuery + `
 			ORDER BY ch.id;
 		`
-		rows, err = pg_conf.GetDB().Query(query)
+		rows, err = r.db.QueryContext(ctx, query, includeRemoved)
 	} else {
 		query := baseQuery + `
-			AND n.node_type_id = $1
+			AND n.node_type_id = $2
 			ORDER BY ch.id;
 		`
-		rows, err = pg_conf.GetDB().Query(query, nodeTypeId)
+		rows, err = r.db.QueryContext(ctx, query, includeRemoved, nodeTypeId)
 	}
 
 	if err != nil {
-		log.Error("Failed to fetch filters", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch filters", zap.Error(err))
 		return nil, err
 	}
 	defer func() {
 		if closeErr := rows.Close(); closeErr != nil {
-			log.Warn("Failed to close rows", zap.Error(closeErr))
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
 		}
 	}()
 
@@ -231,9 +236,52 @@ func (r *characteristicRepository) GetCharFilters(nodeTypeId int) (*[]model.Char
 	// Декодируем строки в срез структур
 	filters, err := utils.DecodeRows[model.CharFiltersRow](rows, scanFunc)
 	if err != nil {
-		log.Error("Failed to decode filters", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to decode filters", zap.Error(err))
 		return nil, err
 	}
 
 	return &filters, nil
 }
+
+// GetCharValueCounts возвращает, сколько подходящих под фильтр карточек имеют каждое значение видимых
+// характеристик. Если characteristicId не 0, считаются только значения этой характеристики.
+func (r *characteristicRepository) GetCharValueCounts(ctx context.Context, filter *model.CardFilter, includeRemoved bool, characteristicId int) ([]model.CharValueCountRow, error) {
+	whereClause, args := cardListWhereClause(filter, includeRemoved)
+	whereClause = appendCondition(whereClause, "fc.is_visible = true")
+	if characteristicId != 0 {
+		args = append(args, characteristicId)
+		whereClause = appendCondition(whereClause, fmt.Sprintf("fc.id = $%d", len(args)))
+	}
+
+	// Алиасы fv/fc не пересекаются с cv/c из EXISTS-подзапросов фильтра
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT fc.id, fv.value, COUNT(DISTINCT n.id)
+		FROM shop.nodes n
+		         JOIN shop.node_types nt ON nt.id = n.node_type_id
+		         JOIN shop.characteristic_values fv ON fv.node_id = n.id
+		         JOIN shop.characteristics fc ON fc.id = fv.characteristic_id
+		%s
+		GROUP BY fc.id, fv.value
+	`, whereClause), args...)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to count characteristic values", zap.Error(err))
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	counts, err := utils.DecodeRows[model.CharValueCountRow](rows, func(rows *sql.Rows) (model.CharValueCountRow, error) {
+		var count model.CharValueCountRow
+		err := rows.Scan(&count.CharacteristicId, &count.Value, &count.Count)
+		return count, err
+	})
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to decode characteristic value counts", zap.Error(err))
+		return nil, err
+	}
+
+	return counts, nil
+}
@@ -1,34 +1,40 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
-	"shop/configs/pg_conf"
 	"shop/internal/api/dto"
 	"shop/internal/model"
+	"shop/pkg/app_error"
 	"shop/pkg/log"
 	"shop/pkg/utils"
 	"time"
 )
 
-type nodeRepository struct{}
+type nodeRepository struct {
+	db txDB
+}
 
 type NodeRepositoryInterface interface {
-	GetAllNodes(pageNumber, pageSize int) ([]model.NodeRow, int, error)
-	CreateNode(size *dto.CreateNodeRequest) (int, error)
-	UpdateNodes(size *dto.UpdateNodeRequest) error
-	DeleteNodeById(id int) error
-	GetNodeById(id int) (*model.NodeRow, error)
+	GetAllNodes(ctx context.Context, pageNumber, pageSize int) ([]model.NodeRow, int, error)
+	CreateNode(ctx context.Context, size *dto.CreateNodeRequest) (int, error)
+	UpdateNodes(ctx context.Context, size *dto.UpdateNodeRequest) error
+	DeleteNodeById(ctx context.Context, id int) (bool, error)
+	GetNodeById(ctx context.Context, id int) (*model.NodeRow, error)
+	CheckNodesByIds(ctx context.Context, ids []int) error
+	RestoreNodeById(ctx context.Context, id int) (bool, error)
+	PurgeRemovedNodes(ctx context.Context, removedBefore time.Time) ([]int, error)
+	RecomputeSearchVectors(ctx context.Context, afterId, limit int) ([]int, error)
 }
 
-func NewNodeRepository() NodeRepositoryInterface {
-	return &nodeRepository{}
+func NewNodeRepository(db *sql.DB) NodeRepositoryInterface {
+	return &nodeRepository{db: txDB{db: db}}
 }
 
-var NodeRepo = NewNodeRepository()
-
-func (r *nodeRepository) GetAllNodes(pageNumber, pageSize int) ([]model.NodeRow, int, error) {
+func (r *nodeRepository) GetAllNodes(ctx context.Context, pageNumber, pageSize int) ([]model.NodeRow, int, error) {
 	if pageNumber < 1 {
 		pageNumber = 1
 	}
@@ -39,20 +45,20 @@ func (r *nodeRepository) GetAllNodes(pageNumber, pageSize int) ([]model.NodeRow,
 	offset := utils.CalculateOffset(pageNumber, pageSize)
 
 	var totalCount int
-	err := pg_conf.GetDB().QueryRow("SELECT COUNT(*) FROM shop.nodes").Scan(&totalCount)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM shop.nodes").Scan(&totalCount)
 	if err != nil {
-		log.Error("Failed to count characteristic", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to count characteristic", zap.Error(err))
 		return nil, 0, err
 	}
 
-	rows, err := pg_conf.GetDB().Query("SELECT id, title, node_type_id, description, created_at, updated_at, removed_at FROM shop.nodes ORDER BY id ASC LIMIT $1 OFFSET $2", pageSize, offset)
+	rows, err := r.db.QueryContext(ctx, "SELECT id, title, node_type_id, description, created_at, updated_at, removed_at FROM shop.nodes ORDER BY id ASC LIMIT $1 OFFSET $2", pageSize, offset)
 	if err != nil {
-		log.Error("Failed to fetch node_types", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch node_types", zap.Error(err))
 		return nil, 0, err
 	}
 	defer func() {
 		if closeErr := rows.Close(); closeErr != nil {
-			log.Warn("Failed to close rows", zap.Error(closeErr))
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
 		}
 	}()
 
@@ -74,16 +80,16 @@ func (r *nodeRepository) GetAllNodes(pageNumber, pageSize int) ([]model.NodeRow,
 
 	nodes, err := utils.DecodeRows[model.NodeRow](rows, scanFunc)
 	if err != nil {
-		log.Error("Failed to decode node", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to decode node", zap.Error(err))
 		return nil, 0, err
 	}
 
 	return nodes, totalCount, nil
 }
 
-func (r *nodeRepository) CreateNode(node *dto.CreateNodeRequest) (int, error) {
+func (r *nodeRepository) CreateNode(ctx context.Context, node *dto.CreateNodeRequest) (int, error) {
 	var insertedID int
-	err := pg_conf.GetDB().QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		"INSERT INTO shop.nodes (title,node_type_id, description) VALUES ($1, $2, $3) RETURNING id",
 		node.Title, node.NodeTypeId, node.Description,
 	).Scan(&insertedID)
@@ -95,35 +101,42 @@ func (r *nodeRepository) CreateNode(node *dto.CreateNodeRequest) (int, error) {
 	return insertedID, nil
 }
 
-func (r *nodeRepository) UpdateNodes(node *dto.UpdateNodeRequest) error {
-	_, err := pg_conf.GetDB().Exec(
+func (r *nodeRepository) UpdateNodes(ctx context.Context, node *dto.UpdateNodeRequest) error {
+	_, err := r.db.ExecContext(ctx,
 		"UPDATE shop.nodes SET title = $1, node_type_id = $2, description = $3 WHERE id = $4",
 		node.Title, node.NodeTypeId, node.Description, node.ID,
 	)
 	return err
 }
 
-func (r *nodeRepository) DeleteNodeById(id int) error {
+// DeleteNodeById помечает ноду удалённой. Возвращает false, если ноды нет или она уже удалена:
+// повторное удаление не сдвигает removed_at и не продлевает срок до очистки.
+func (r *nodeRepository) DeleteNodeById(ctx context.Context, id int) (bool, error) {
 	// Get the current time in UTC
 	currentTime := time.Now().UTC()
 
 	// Execute the UPDATE statement with currentTime and id as parameters
-	_, err := pg_conf.GetDB().Exec(
-		"UPDATE shop.nodes SET removed_at = $1 WHERE id = $2",
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE shop.nodes SET removed_at = $1 WHERE id = $2 AND removed_at IS NULL",
 		currentTime,
 		id,
 	)
 	if err != nil {
 		// Log the error with context for easier debugging
-		log.Error("Failed to delete node", zap.Int("id", id), zap.Error(err))
-		return err
+		log.FromContext(ctx).Error("Failed to delete node", zap.Int("id", id), zap.Error(err))
+		return false, err
 	}
-	return nil
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
 }
-func (r *nodeRepository) GetNodeById(id int) (*model.NodeRow, error) {
+func (r *nodeRepository) GetNodeById(ctx context.Context, id int) (*model.NodeRow, error) {
 	var node model.NodeRow
 
-	err := pg_conf.GetDB().QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		"SELECT id, title, node_type_id, description, created_at, updated_at, removed_at FROM shop.nodes WHERE id = $1",
 		id,
 	).Scan(
@@ -138,12 +151,145 @@ func (r *nodeRepository) GetNodeById(id int) (*model.NodeRow, error) {
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			log.Warn("Node not found", zap.Int("id", id))
-			return nil, errors.New("node not found")
+			log.FromContext(ctx).Warn("Node not found", zap.Int("id", id))
+			return nil, app_error.NotFound("Node not found")
 		}
-		log.Error("Failed to fetch node by ID", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch node by ID", zap.Error(err))
 		return nil, err
 	}
 
 	return &node, nil
 }
+
+// CheckNodesByIds проверяет, что все переданные ноды существуют и не удалены.
+func (r *nodeRepository) CheckNodesByIds(ctx context.Context, ids []int) error {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id FROM shop.nodes WHERE id = ANY($1) AND removed_at IS NULL",
+		pq.Array(ids),
+	)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to fetch nodes by IDs", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	// Сохраняем найденные ID
+	foundIDs := make(map[int]struct{})
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			log.FromContext(ctx).Error("Failed to scan node row", zap.Error(err))
+			return err
+		}
+		foundIDs[id] = struct{}{}
+	}
+
+	if err := rows.Err(); err != nil {
+		log.FromContext(ctx).Error("Error occurred during rows iteration", zap.Error(err))
+		return err
+	}
+
+	// Определяем отсутствующие ID
+	missingIDs := []int{}
+	for _, id := range ids {
+		if _, exists := foundIDs[id]; !exists {
+			missingIDs = append(missingIDs, id)
+		}
+	}
+
+	if len(missingIDs) > 0 {
+		log.FromContext(ctx).Warn("Some node IDs are missing", zap.Ints("missingIDs", missingIDs))
+		return app_error.Validation("these node IDs don't exist: %v", missingIDs)
+	}
+
+	return nil
+}
+
+// RestoreNodeById снимает пометку об удалении. Возвращает false, если нода не была удалена.
+func (r *nodeRepository) RestoreNodeById(ctx context.Context, id int) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE shop.nodes SET removed_at = NULL, updated_at = NOW() WHERE id = $1 AND removed_at IS NOT NULL",
+		id,
+	)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to restore node", zap.Int("id", id), zap.Error(err))
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// PurgeRemovedNodes физически удаляет ноды, помеченные удалёнными раньше removedBefore.
+// Ноды, которые есть в заказах, не удаляются: позиции заказов ссылаются на них.
+// Возвращает id удалённых нод.
+func (r *nodeRepository) PurgeRemovedNodes(ctx context.Context, removedBefore time.Time) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		DELETE FROM shop.nodes n
+		WHERE n.removed_at IS NOT NULL
+		  AND n.removed_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM shop.order_items oi WHERE oi.node_id = n.id)
+		RETURNING n.id`,
+		removedBefore,
+	)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to purge removed nodes", zap.Error(err))
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	ids, err := utils.DecodeRows[int](rows, func(rows *sql.Rows) (int, error) {
+		var id int
+		err := rows.Scan(&id)
+		return id, err
+	})
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to decode purged node ids", zap.Error(err))
+		return nil, err
+	}
+
+	if ids == nil {
+		ids = make([]int, 0)
+	}
+
+	return ids, nil
+}
+
+// RecomputeSearchVectors пересчитывает search_vector у следующих limit нод с id > afterId
+// (в порядке id) и возвращает их id. Сам вектор строит триггер tr_update_nodes_search_vector,
+// updated_at при этом не меняется.
+func (r *nodeRepository) RecomputeSearchVectors(ctx context.Context, afterId, limit int) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE shop.nodes
+		SET search_vector = NULL
+		WHERE id IN (SELECT id
+		             FROM shop.nodes
+		             WHERE id > $1
+		             ORDER BY id
+		             LIMIT $2)
+		RETURNING id`,
+		afterId, limit,
+	)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to recompute search vectors", zap.Error(err))
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	return utils.DecodeRows[int](rows, func(rows *sql.Rows) (int, error) {
+		var id int
+		err := rows.Scan(&id)
+		return id, err
+	})
+}
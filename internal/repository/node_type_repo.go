@@ -1,33 +1,34 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"go.uber.org/zap"
-	"shop/configs/pg_conf"
 	"shop/internal/api/dto"
 	"shop/internal/model"
+	"shop/pkg/app_error"
 	"shop/pkg/log"
 	"shop/pkg/utils"
 )
 
-type nodeTypeRepository struct{}
+type nodeTypeRepository struct {
+	db txDB
+}
 
 type NodeTypeRepositoryInterface interface {
-	GetAllNodeTypes(pageNumber, pageSize int) ([]model.NodeTypeRow, int, error)
-	CreateNodeType(size *dto.CreateNodeTypeRequest) (int, error)
-	UpdateNodeType(size *model.NodeTypeRow) error
-	DeleteNodeTypeById(id int) error
-	GetNodeTypeById(id int) (*model.NodeTypeRow, error)
+	GetAllNodeTypes(ctx context.Context, pageNumber, pageSize int) ([]model.NodeTypeRow, int, error)
+	CreateNodeType(ctx context.Context, size *dto.CreateNodeTypeRequest) (int, error)
+	UpdateNodeType(ctx context.Context, size *model.NodeTypeRow) error
+	DeleteNodeTypeById(ctx context.Context, id int) error
+	GetNodeTypeById(ctx context.Context, id int) (*model.NodeTypeRow, error)
 }
 
-func NewNodeTypeRepository() NodeTypeRepositoryInterface {
-	return &nodeTypeRepository{}
+func NewNodeTypeRepository(db *sql.DB) NodeTypeRepositoryInterface {
+	return &nodeTypeRepository{db: txDB{db: db}}
 }
 
-var NodeTypeRepo = NewNodeTypeRepository()
-
-func (r *nodeTypeRepository) GetAllNodeTypes(pageNumber, pageSize int) ([]model.NodeTypeRow, int, error) {
+func (r *nodeTypeRepository) GetAllNodeTypes(ctx context.Context, pageNumber, pageSize int) ([]model.NodeTypeRow, int, error) {
 	if pageNumber < 1 {
 		pageNumber = 1
 	}
@@ -38,26 +39,26 @@ func (r *nodeTypeRepository) GetAllNodeTypes(pageNumber, pageSize int) ([]model.
 	offset := utils.CalculateOffset(pageNumber, pageSize)
 
 	var totalCount int
-	err := pg_conf.GetDB().QueryRow("SELECT COUNT(*) FROM shop.node_types").Scan(&totalCount)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM shop.node_types").Scan(&totalCount)
 	if err != nil {
-		log.Error("Failed to count characteristic", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to count characteristic", zap.Error(err))
 		return nil, 0, err
 	}
 
-	rows, err := pg_conf.GetDB().Query("SELECT id, type, description FROM shop.node_types ORDER BY id ASC LIMIT $1 OFFSET $2", pageSize, offset)
+	rows, err := r.db.QueryContext(ctx, "SELECT id, type, description, search_config::text FROM shop.node_types ORDER BY id ASC LIMIT $1 OFFSET $2", pageSize, offset)
 	if err != nil {
-		log.Error("Failed to fetch node_types", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch node_types", zap.Error(err))
 		return nil, 0, err
 	}
 	defer func() {
 		if closeErr := rows.Close(); closeErr != nil {
-			log.Warn("Failed to close rows", zap.Error(closeErr))
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
 		}
 	}()
 
 	scanFunc := func(rows *sql.Rows) (model.NodeTypeRow, error) {
 		var size model.NodeTypeRow
-		if err := rows.Scan(&size.ID, &size.Type, &size.Description); err != nil {
+		if err := rows.Scan(&size.ID, &size.Type, &size.Description, &size.SearchConfig); err != nil {
 			return model.NodeTypeRow{}, err
 		}
 		return size, nil
@@ -65,18 +66,18 @@ func (r *nodeTypeRepository) GetAllNodeTypes(pageNumber, pageSize int) ([]model.
 
 	sizes, err := utils.DecodeRows[model.NodeTypeRow](rows, scanFunc)
 	if err != nil {
-		log.Error("Failed to decode node_type", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to decode node_type", zap.Error(err))
 		return nil, 0, err
 	}
 
 	return sizes, totalCount, nil
 }
 
-func (r *nodeTypeRepository) CreateNodeType(size *dto.CreateNodeTypeRequest) (int, error) {
+func (r *nodeTypeRepository) CreateNodeType(ctx context.Context, size *dto.CreateNodeTypeRequest) (int, error) {
 	var insertedID int
-	err := pg_conf.GetDB().QueryRow(
-		"INSERT INTO shop.node_types (type, description) VALUES ($1, $2) RETURNING id",
-		size.Type, size.Description,
+	err := r.db.QueryRowContext(ctx,
+		"INSERT INTO shop.node_types (type, description, search_config) VALUES ($1, $2, COALESCE($3::text, $4)::regconfig) RETURNING id",
+		size.Type, size.Description, size.SearchConfig, model.DefaultSearchConfig,
 	).Scan(&insertedID)
 
 	if err != nil {
@@ -86,33 +87,33 @@ func (r *nodeTypeRepository) CreateNodeType(size *dto.CreateNodeTypeRequest) (in
 	return insertedID, nil
 }
 
-func (r *nodeTypeRepository) UpdateNodeType(size *model.NodeTypeRow) error {
-	_, err := pg_conf.GetDB().Exec(
-		"UPDATE shop.node_types SET type = $1, description = $2 WHERE id = $3",
-		size.Type, size.Description, size.ID,
+func (r *nodeTypeRepository) UpdateNodeType(ctx context.Context, size *model.NodeTypeRow) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE shop.node_types SET type = $1, description = $2, search_config = $3::regconfig WHERE id = $4",
+		size.Type, size.Description, size.SearchConfig, size.ID,
 	)
 	return err
 }
 
-func (r *nodeTypeRepository) DeleteNodeTypeById(id int) error {
-	_, err := pg_conf.GetDB().Exec("DELETE FROM shop.node_types WHERE id = $1", id)
+func (r *nodeTypeRepository) DeleteNodeTypeById(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM shop.node_types WHERE id = $1", id)
 	return err
 }
 
-func (r *nodeTypeRepository) GetNodeTypeById(id int) (*model.NodeTypeRow, error) {
+func (r *nodeTypeRepository) GetNodeTypeById(ctx context.Context, id int) (*model.NodeTypeRow, error) {
 	var size model.NodeTypeRow
 
-	err := pg_conf.GetDB().QueryRow(
-		"SELECT id, type, description FROM shop.node_types WHERE id = $1",
+	err := r.db.QueryRowContext(ctx,
+		"SELECT id, type, description, search_config::text FROM shop.node_types WHERE id = $1",
 		id,
-	).Scan(&size.ID, &size.Type, &size.Description)
+	).Scan(&size.ID, &size.Type, &size.Description, &size.SearchConfig)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			log.Warn("node_type not found", zap.Int("id", id))
-			return nil, errors.New("node_type not found")
+			log.FromContext(ctx).Warn("node_type not found", zap.Int("id", id))
+			return nil, app_error.NotFound("NodeType not found")
 		}
-		log.Error("Failed to fetch node_type by ID", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch node_type by ID", zap.Error(err))
 		return nil, err
 	}
 
@@ -0,0 +1,447 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"shop/internal/api/dto"
+	"shop/internal/model"
+	"shop/pkg/app_error"
+	"shop/pkg/log"
+	"shop/pkg/utils"
+	"sort"
+	"strings"
+)
+
+// discountCharTitle — название характеристики, в которой хранится скидка товара в процентах.
+const discountCharTitle = model.DiscountCharTitle
+
+// discountValueExpr — скидка в процентах из значения характеристики cv.value, разобранная так же,
+// как model.ParseDiscount; NULL, если значение не начинается с числа.
+var discountValueExpr = fmt.Sprintf("substring(cv.value from %s)::int", pq.QuoteLiteral(model.DiscountPattern))
+
+// ErrOrderStatusConflict возвращается, если статус заказа изменился между чтением и обновлением.
+var ErrOrderStatusConflict = errors.New("order status was changed concurrently")
+
+type orderRepository struct {
+	db txDB
+}
+
+type OrderRepositoryInterface interface {
+	CreateOrder(ctx context.Context, items []dto.OrderDTO, userId *int) (string, error)
+	GetOrderById(ctx context.Context, id string) (*model.OrderRow, error)
+	GetOrderItems(ctx context.Context, orderId string) ([]model.OrderItemRow, error)
+	GetAllOrders(ctx context.Context, pageNumber, pageSize int, filter *model.OrderFilter) ([]model.OrderRow, int, error)
+	UpdateOrderStatus(ctx context.Context, id, fromStatus, toStatus string, changedBy, comment *string) error
+	GetOrderStatusHistory(ctx context.Context, orderId string) ([]model.OrderStatusHistoryRow, error)
+}
+
+func NewOrderRepository(db *sql.DB) OrderRepositoryInterface {
+	return &orderRepository{db: txDB{db: db}}
+}
+
+// CreateOrder сохраняет заказ и все его позиции в одной транзакции.
+func (r *orderRepository) CreateOrder(ctx context.Context, items []dto.OrderDTO, userId *int) (string, error) {
+	orderId := uuid.New().String()
+
+	err := r.db.WithinTx(ctx, func(ctx context.Context) error {
+		// 1. Вставляем запись в shop.orders
+		if _, err := r.db.ExecContext(ctx,
+			"INSERT INTO shop.orders (id, user_id, status) VALUES ($1, $2, $3)",
+			orderId, userId, model.OrderStatusNew,
+		); err != nil {
+			log.FromContext(ctx).Error("Failed to insert order", zap.Error(err))
+			return err
+		}
+
+		// 2. Фиксируем начальный статус в истории
+		if err := r.insertStatusHistoryTx(ctx, orderId, nil, model.OrderStatusNew, nil, nil); err != nil {
+			log.FromContext(ctx).Error("Failed to insert order status history", zap.Error(err))
+			return err
+		}
+
+		// 3. Резервируем остатки в порядке stockLockOrder, чтобы параллельные заказы блокировали
+		//    строки shop.stock в одном порядке и не попадали во взаимную блокировку.
+		//    Недоступные позиции собираем все сразу, чтобы вернуть клиенту полный список.
+		sizeIds := make([]*int, len(items))
+		var unavailable []model.UnavailableStockItem
+		for _, i := range stockLockOrder(items) {
+			sizeId, missing, err := reserveStockTx(ctx, r.db, items[i])
+			if err != nil {
+				log.FromContext(ctx).Error("Failed to reserve stock", zap.Int("nodeId", items[i].NodeId), zap.Error(err))
+				return err
+			}
+			if missing != nil {
+				missing.Index = i
+				unavailable = append(unavailable, *missing)
+				continue
+			}
+			sizeIds[i] = sizeId
+		}
+
+		if len(unavailable) > 0 {
+			sort.Slice(unavailable, func(a, b int) bool { return unavailable[a].Index < unavailable[b].Index })
+			log.FromContext(ctx).Warn("Not enough stock for order", zap.Any("items", unavailable))
+			return &ErrStockUnavailable{Items: unavailable}
+		}
+
+		// 4. Вставляем позиции в порядке запроса вместе со снимком цены и скидки
+		for i, item := range items {
+			if err := r.insertOrderItemTx(ctx, orderId, item, sizeIds[i]); err != nil {
+				log.FromContext(ctx).Error("Failed to insert order item", zap.Int("nodeId", item.NodeId), zap.Error(err))
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	log.FromContext(ctx).Info("Order successfully created", zap.String("orderId", orderId))
+	return orderId, nil
+}
+
+// stockLockOrder возвращает индексы позиций заказа, упорядоченные по (nodeId, размер).
+// Строка shop.stock однозначно определяется нодой и названием размера, поэтому такой порядок
+// совпадает с порядком (node_id, size_id) для всех транзакций. Позиции без размера идут первыми.
+func stockLockOrder(items []dto.OrderDTO) []int {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		x, y := items[order[a]], items[order[b]]
+		if x.NodeId != y.NodeId {
+			return x.NodeId < y.NodeId
+		}
+		if x.Size == nil || y.Size == nil {
+			return x.Size == nil && y.Size != nil
+		}
+		return *x.Size < *y.Size
+	})
+	return order
+}
+
+// insertOrderItemTx — вспомогательная функция, вставляет позицию заказа,
+// копируя price_byn, price_rub и скидку из карточки на момент оформления.
+// sizeId — размер, под который зарезервирован остаток (nil, если остатки по ноде не ведутся).
+func (r *orderRepository) insertOrderItemTx(ctx context.Context, orderId string, item dto.OrderDTO, sizeId *int) error {
+	query := `
+		INSERT INTO shop.order_items (order_id, node_id, size, size_id, amount, price_byn, price_rub, discount)
+		SELECT $1,
+		       n.id,
+		       $3,
+		       $6,
+		       $4,
+		       n.price_byn,
+		       n.price_rub,
+		       (SELECT ` + discountValueExpr + `
+		        FROM shop.characteristic_values cv
+		                 JOIN shop.characteristics c ON c.id = cv.characteristic_id
+		        WHERE cv.node_id = n.id
+		          AND c.title = $5
+		        LIMIT 1)
+		FROM shop.nodes n
+		WHERE n.id = $2
+		  AND n.removed_at IS NULL
+	`
+
+	res, err := r.db.ExecContext(ctx, query, orderId, item.NodeId, item.Size, item.Amount, discountCharTitle, sizeId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return app_error.Validation("Node %d not found", item.NodeId)
+	}
+	return nil
+}
+
+func (r *orderRepository) GetOrderById(ctx context.Context, id string) (*model.OrderRow, error) {
+	var order model.OrderRow
+
+	err := r.db.QueryRowContext(ctx,
+		"SELECT id, user_id, status, created_at, updated_at FROM shop.orders WHERE id = $1",
+		id,
+	).Scan(&order.ID, &order.UserId, &order.Status, &order.CreatedAt, &order.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.FromContext(ctx).Warn("Order not found", zap.String("id", id))
+			return nil, app_error.NotFound("Order not found")
+		}
+		log.FromContext(ctx).Error("Failed to fetch order by ID", zap.Error(err))
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func (r *orderRepository) GetOrderItems(ctx context.Context, orderId string) ([]model.OrderItemRow, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT oi.id,
+		       oi.order_id,
+		       oi.node_id,
+		       n.title,
+		       oi.size,
+		       oi.amount,
+		       oi.price_byn,
+		       oi.price_rub,
+		       oi.discount
+		FROM shop.order_items oi
+		         JOIN shop.nodes n ON n.id = oi.node_id
+		WHERE oi.order_id = $1
+		ORDER BY oi.id ASC`, orderId)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to fetch order items", zap.Error(err))
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	scanFunc := func(rows *sql.Rows) (model.OrderItemRow, error) {
+		var item model.OrderItemRow
+		if err := rows.Scan(
+			&item.ID,
+			&item.OrderId,
+			&item.NodeId,
+			&item.Title,
+			&item.Size,
+			&item.Amount,
+			&item.PriceByn,
+			&item.PriceRub,
+			&item.Discount,
+		); err != nil {
+			return model.OrderItemRow{}, err
+		}
+		return item, nil
+	}
+
+	items, err := utils.DecodeRows[model.OrderItemRow](rows, scanFunc)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to decode order items", zap.Error(err))
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *orderRepository) GetAllOrders(ctx context.Context, pageNumber, pageSize int, filter *model.OrderFilter) ([]model.OrderRow, int, error) {
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	offset := utils.CalculateOffset(pageNumber, pageSize)
+
+	whereClause, whereArgs := buildOrderWhereClause(filter)
+
+	var totalCount int
+	countQuery := "SELECT COUNT(*) FROM shop.orders o " + whereClause
+	if err := r.db.QueryRowContext(ctx, countQuery, whereArgs...).Scan(&totalCount); err != nil {
+		log.FromContext(ctx).Error("Failed to count orders", zap.Error(err))
+		return nil, 0, err
+	}
+
+	args := make([]interface{}, 0, len(whereArgs)+2)
+	args = append(args, whereArgs...)
+	args = append(args, pageSize, offset)
+
+	selectQuery := fmt.Sprintf(`
+		SELECT o.id, o.user_id, o.status, o.created_at, o.updated_at
+		FROM shop.orders o
+		%s
+		ORDER BY o.created_at DESC, o.id
+		LIMIT $%d OFFSET $%d`,
+		whereClause,
+		len(whereArgs)+1,
+		len(whereArgs)+2,
+	)
+
+	rows, err := r.db.QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to fetch orders", zap.Error(err))
+		return nil, 0, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	scanFunc := func(rows *sql.Rows) (model.OrderRow, error) {
+		var order model.OrderRow
+		if err := rows.Scan(&order.ID, &order.UserId, &order.Status, &order.CreatedAt, &order.UpdatedAt); err != nil {
+			return model.OrderRow{}, err
+		}
+		return order, nil
+	}
+
+	orders, err := utils.DecodeRows[model.OrderRow](rows, scanFunc)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to decode orders", zap.Error(err))
+		return nil, 0, err
+	}
+
+	if orders == nil {
+		orders = make([]model.OrderRow, 0)
+	}
+
+	return orders, totalCount, nil
+}
+
+// buildOrderWhereClause формирует часть WHERE для списка заказов по владельцу, статусам и периоду создания.
+func buildOrderWhereClause(filter *model.OrderFilter) (string, []interface{}) {
+	if filter == nil {
+		return "", nil
+	}
+
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	if filter.UserId != nil {
+		args = append(args, *filter.UserId)
+		conditions = append(conditions, fmt.Sprintf("o.user_id = $%d", len(args)))
+	}
+	if len(filter.Statuses) > 0 {
+		args = append(args, pq.Array(filter.Statuses))
+		conditions = append(conditions, fmt.Sprintf("o.status = ANY($%d)", len(args)))
+	}
+	if filter.DateFrom != nil {
+		args = append(args, *filter.DateFrom)
+		conditions = append(conditions, fmt.Sprintf("o.created_at >= $%d", len(args)))
+	}
+	if filter.DateTo != nil {
+		args = append(args, *filter.DateTo)
+		conditions = append(conditions, fmt.Sprintf("o.created_at < $%d", len(args)))
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// UpdateOrderStatus переводит заказ из статуса fromStatus в toStatus и записывает переход в историю.
+// Если к моменту обновления статус заказа уже отличается от fromStatus, возвращается ErrOrderStatusConflict.
+func (r *orderRepository) UpdateOrderStatus(ctx context.Context, id, fromStatus, toStatus string, changedBy, comment *string) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		res, err := r.db.ExecContext(ctx,
+			"UPDATE shop.orders SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3",
+			toStatus, id, fromStatus,
+		)
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to update order status", zap.String("id", id), zap.Error(err))
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			log.FromContext(ctx).Warn("Order status changed concurrently", zap.String("id", id), zap.String("expected", fromStatus))
+			return ErrOrderStatusConflict
+		}
+
+		// Отмена снимает резерв, отгрузка списывает зарезервированные единицы со склада,
+		// возврат (только после отгрузки) возвращает их на склад
+		switch toStatus {
+		case model.OrderStatusCancelled:
+			err = releaseStockTx(ctx, r.db, id)
+		case model.OrderStatusShipped:
+			err = consumeStockTx(ctx, r.db, id)
+		case model.OrderStatusReturned:
+			err = restockTx(ctx, r.db, id)
+		}
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to update stock for order", zap.String("id", id), zap.Error(err))
+			return err
+		}
+
+		if err := r.insertStatusHistoryTx(ctx, id, &fromStatus, toStatus, changedBy, comment); err != nil {
+			log.FromContext(ctx).Error("Failed to insert order status history", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
+
+// insertStatusHistoryTx — вспомогательная функция, добавляет запись в shop.order_status_history.
+func (r *orderRepository) insertStatusHistoryTx(
+	ctx context.Context,
+	orderId string,
+	fromStatus *string,
+	toStatus string,
+	changedBy, comment *string,
+) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO shop.order_status_history (order_id, from_status, to_status, changed_by, comment)
+		VALUES ($1, $2, $3, $4, $5)`,
+		orderId, fromStatus, toStatus, changedBy, comment,
+	)
+	return err
+}
+
+func (r *orderRepository) GetOrderStatusHistory(ctx context.Context, orderId string) ([]model.OrderStatusHistoryRow, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, order_id, from_status, to_status, changed_by, comment, changed_at
+		FROM shop.order_status_history
+		WHERE order_id = $1
+		ORDER BY changed_at ASC, id ASC`, orderId)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to fetch order status history", zap.Error(err))
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	scanFunc := func(rows *sql.Rows) (model.OrderStatusHistoryRow, error) {
+		var row model.OrderStatusHistoryRow
+		if err := rows.Scan(
+			&row.ID,
+			&row.OrderId,
+			&row.FromStatus,
+			&row.ToStatus,
+			&row.ChangedBy,
+			&row.Comment,
+			&row.ChangedAt,
+		); err != nil {
+			return model.OrderStatusHistoryRow{}, err
+		}
+		return row, nil
+	}
+
+	history, err := utils.DecodeRows[model.OrderStatusHistoryRow](rows, scanFunc)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to decode order status history", zap.Error(err))
+		return nil, err
+	}
+
+	if history == nil {
+		history = make([]model.OrderStatusHistoryRow, 0)
+	}
+
+	return history, nil
+}
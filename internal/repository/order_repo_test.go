@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"github.com/stretchr/testify/assert"
+	"shop/internal/api/dto"
+	"testing"
+)
+
+func strPtr(v string) *string { return &v }
+
+func TestStockLockOrder(t *testing.T) {
+	items := []dto.OrderDTO{
+		{NodeId: 5, Size: strPtr("M"), Amount: 1},
+		{NodeId: 2, Size: strPtr("XL"), Amount: 1},
+		{NodeId: 5, Size: strPtr("L"), Amount: 1},
+		{NodeId: 2, Amount: 1},
+		{NodeId: 2, Size: strPtr("L"), Amount: 1},
+	}
+
+	assert.Equal(t, []int{3, 4, 1, 2, 0}, stockLockOrder(items))
+
+	// Порядок не зависит от порядка позиций в запросе
+	reversed := []dto.OrderDTO{items[4], items[3], items[2], items[1], items[0]}
+	locked := make([]dto.OrderDTO, 0, len(reversed))
+	for _, i := range stockLockOrder(reversed) {
+		locked = append(locked, reversed[i])
+	}
+	expected := make([]dto.OrderDTO, 0, len(items))
+	for _, i := range stockLockOrder(items) {
+		expected = append(expected, items[i])
+	}
+	assert.Equal(t, expected, locked)
+
+	assert.Empty(t, stockLockOrder(nil))
+}
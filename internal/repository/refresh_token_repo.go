@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go.uber.org/zap"
+	"shop/internal/model"
+	"shop/pkg/app_error"
+	"shop/pkg/log"
+)
+
+// ErrRefreshTokenReused возвращается, если токен уже был обменян или отозван к моменту ротации.
+var ErrRefreshTokenReused = errors.New("refresh token was already used")
+
+type refreshTokenRepository struct {
+	db txDB
+}
+
+type RefreshTokenRepositoryInterface interface {
+	CreateRefreshToken(ctx context.Context, token *model.RefreshTokenRow) error
+	GetRefreshTokenById(ctx context.Context, id string) (*model.RefreshTokenRow, error)
+	RotateRefreshToken(ctx context.Context, oldId string, newToken *model.RefreshTokenRow) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyId string) error
+}
+
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepositoryInterface {
+	return &refreshTokenRepository{db: txDB{db: db}}
+}
+
+func (r *refreshTokenRepository) CreateRefreshToken(ctx context.Context, token *model.RefreshTokenRow) error {
+	return r.insertRefreshToken(ctx, token)
+}
+
+func (r *refreshTokenRepository) GetRefreshTokenById(ctx context.Context, id string) (*model.RefreshTokenRow, error) {
+	var token model.RefreshTokenRow
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, family_id, user_id, token_hash, expires_at, rotated_at, revoked_at
+		FROM shop.refresh_tokens
+		WHERE id = $1`, id,
+	).Scan(
+		&token.ID,
+		&token.FamilyId,
+		&token.UserId,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.RotatedAt,
+		&token.RevokedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.FromContext(ctx).Warn("Refresh token not found", zap.String("id", id))
+			return nil, app_error.NotFound("Refresh token not found")
+		}
+		log.FromContext(ctx).Error("Failed to fetch refresh token", zap.Error(err))
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// RotateRefreshToken помечает токен oldId обменянным и сохраняет newToken в той же транзакции.
+// Условный UPDATE гарантирует, что из двух параллельных обменов одного токена успешен только один,
+// второй получает ErrRefreshTokenReused.
+func (r *refreshTokenRepository) RotateRefreshToken(ctx context.Context, oldId string, newToken *model.RefreshTokenRow) error {
+	return r.db.WithinTx(ctx, func(ctx context.Context) error {
+		// 1. Помечаем старый токен обменянным, если он ещё действителен
+		res, err := r.db.ExecContext(ctx, `
+			UPDATE shop.refresh_tokens
+			SET rotated_at = NOW()
+			WHERE id = $1
+			  AND rotated_at IS NULL
+			  AND revoked_at IS NULL`,
+			oldId,
+		)
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to rotate refresh token", zap.Error(err))
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrRefreshTokenReused
+		}
+
+		// 2. Сохраняем новый токен той же семьи
+		if err := r.insertRefreshToken(ctx, newToken); err != nil {
+			log.FromContext(ctx).Error("Failed to insert refresh token", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
+
+// RevokeRefreshTokenFamily отзывает все ещё не отозванные токены семьи.
+func (r *refreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyId string) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE shop.refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL",
+		familyId,
+	)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to revoke refresh token family", zap.String("familyId", familyId), zap.Error(err))
+	}
+	return err
+}
+
+// insertRefreshToken сохраняет refresh-токен, в том числе внутри открытой транзакции.
+func (r *refreshTokenRepository) insertRefreshToken(ctx context.Context, token *model.RefreshTokenRow) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO shop.refresh_tokens (id, family_id, user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		token.ID, token.FamilyId, token.UserId, token.TokenHash, token.ExpiresAt,
+	)
+	return err
+}
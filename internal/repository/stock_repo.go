@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"shop/internal/api/dto"
+	"shop/internal/model"
+	"shop/pkg/app_error"
+	"shop/pkg/log"
+	"shop/pkg/utils"
+	"strings"
+)
+
+// ErrStockUnavailable возвращается при оформлении заказа, если для части позиций не хватило остатков.
+type ErrStockUnavailable struct {
+	Items []model.UnavailableStockItem
+}
+
+func (e *ErrStockUnavailable) Error() string {
+	parts := make([]string, 0, len(e.Items))
+	for _, item := range e.Items {
+		parts = append(parts, fmt.Sprintf("node %d: requested %d, available %d", item.NodeId, item.Requested, item.Available))
+	}
+	return "not enough stock: " + strings.Join(parts, "; ")
+}
+
+type stockRepository struct {
+	db txDB
+}
+
+type StockRepositoryInterface interface {
+	GetAllStock(ctx context.Context, pageNumber, pageSize, nodeId int) ([]model.StockRow, int, error)
+	CreateStock(ctx context.Context, stock *dto.CreateStockRequest) (int, error)
+	UpdateStock(ctx context.Context, stock *dto.UpdateStockRequest) error
+	DeleteStockById(ctx context.Context, id int) error
+	GetStockById(ctx context.Context, id int) (*model.StockRow, error)
+}
+
+func NewStockRepository(db *sql.DB) StockRepositoryInterface {
+	return &stockRepository{db: txDB{db: db}}
+}
+
+const stockSelect = `
+	SELECT s.id,
+	       s.node_id,
+	       s.size_id,
+	       sz.title,
+	       s.quantity,
+	       s.reserved,
+	       s.quantity - s.reserved AS available
+	FROM shop.stock s
+	         JOIN shop.size sz ON sz.id = s.size_id
+`
+
+func scanStockRow(rows *sql.Rows) (model.StockRow, error) {
+	var stock model.StockRow
+	if err := rows.Scan(
+		&stock.ID,
+		&stock.NodeId,
+		&stock.SizeId,
+		&stock.Size,
+		&stock.Quantity,
+		&stock.Reserved,
+		&stock.Available,
+	); err != nil {
+		return model.StockRow{}, err
+	}
+	return stock, nil
+}
+
+// GetAllStock возвращает остатки с пагинацией. Если nodeId != 0, выборка ограничивается одной нодой.
+func (r *stockRepository) GetAllStock(ctx context.Context, pageNumber, pageSize, nodeId int) ([]model.StockRow, int, error) {
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	offset := utils.CalculateOffset(pageNumber, pageSize)
+
+	var totalCount int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM shop.stock WHERE $1 = 0 OR node_id = $1",
+		nodeId,
+	).Scan(&totalCount)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to count stock", zap.Error(err))
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		stockSelect+" WHERE $1 = 0 OR s.node_id = $1 ORDER BY s.node_id ASC, sz.title ASC LIMIT $2 OFFSET $3",
+		nodeId, pageSize, offset,
+	)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to fetch stock", zap.Error(err))
+		return nil, 0, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	stock, err := utils.DecodeRows[model.StockRow](rows, scanStockRow)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to decode stock", zap.Error(err))
+		return nil, 0, err
+	}
+
+	if stock == nil {
+		stock = make([]model.StockRow, 0)
+	}
+
+	return stock, totalCount, nil
+}
+
+func (r *stockRepository) CreateStock(ctx context.Context, stock *dto.CreateStockRequest) (int, error) {
+	var insertedID int
+	err := r.db.QueryRowContext(ctx,
+		"INSERT INTO shop.stock (node_id, size_id, quantity) VALUES ($1, $2, $3) RETURNING id",
+		stock.NodeId, stock.SizeId, stock.Quantity,
+	).Scan(&insertedID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return insertedID, nil
+}
+
+func (r *stockRepository) UpdateStock(ctx context.Context, stock *dto.UpdateStockRequest) error {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE shop.stock SET quantity = $1 WHERE id = $2",
+		stock.Quantity, stock.ID,
+	)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to update stock", zap.Int("id", stock.ID), zap.Error(err))
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		log.FromContext(ctx).Warn("Stock not found", zap.Int("id", stock.ID))
+		return app_error.NotFound("Stock not found")
+	}
+	return nil
+}
+
+func (r *stockRepository) DeleteStockById(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM shop.stock WHERE id = $1", id)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to delete stock", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		log.FromContext(ctx).Warn("Stock not found", zap.Int("id", id))
+		return app_error.NotFound("Stock not found")
+	}
+	return nil
+}
+
+func (r *stockRepository) GetStockById(ctx context.Context, id int) (*model.StockRow, error) {
+	rows, err := r.db.QueryContext(ctx, stockSelect+" WHERE s.id = $1", id)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to fetch stock by ID", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	stock, err := utils.DecodeRows[model.StockRow](rows, scanStockRow)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to decode stock", zap.Error(err))
+		return nil, err
+	}
+
+	if len(stock) == 0 {
+		log.FromContext(ctx).Warn("Stock not found", zap.Int("id", id))
+		return nil, app_error.NotFound("Stock not found")
+	}
+
+	return &stock[0], nil
+}
+
+// reserveStockTx резервирует остаток под позицию заказа условным UPDATE, который
+// одновременно блокирует строку и проверяет, что свободных единиц достаточно.
+//
+// Ноды, для которых в shop.stock нет ни одной строки, считаются товаром без учёта
+// остатков: для них возвращается sizeId == nil и ничего не резервируется.
+// Если остатков не хватает, возвращается unavailable с количеством свободных единиц.
+func reserveStockTx(ctx context.Context, q Querier, item dto.OrderDTO) (sizeId *int, unavailable *model.UnavailableStockItem, err error) {
+	if item.Size != nil {
+		var reservedSizeId int
+		err = q.QueryRowContext(ctx, `
+			UPDATE shop.stock s
+			SET reserved = s.reserved + $3
+			FROM shop.size sz
+			WHERE sz.id = s.size_id
+			  AND s.node_id = $1
+			  AND sz.title = $2
+			  AND s.quantity - s.reserved >= $3
+			RETURNING s.size_id`,
+			item.NodeId, *item.Size, item.Amount,
+		).Scan(&reservedSizeId)
+		if err == nil {
+			return &reservedSizeId, nil, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, err
+		}
+	}
+
+	// Резерв не удался: выясняем, ведётся ли учёт остатков по ноде и сколько свободно.
+	var (
+		tracked   bool
+		available int
+	)
+	err = q.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM shop.stock WHERE node_id = $1),
+		       COALESCE((SELECT s.quantity - s.reserved
+		                 FROM shop.stock s
+		                          JOIN shop.size sz ON sz.id = s.size_id
+		                 WHERE s.node_id = $1
+		                   AND sz.title = $2), 0)`,
+		item.NodeId, item.Size,
+	).Scan(&tracked, &available)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !tracked {
+		return nil, nil, nil
+	}
+
+	return nil, &model.UnavailableStockItem{
+		NodeId:    item.NodeId,
+		Size:      item.Size,
+		Requested: item.Amount,
+		Available: available,
+	}, nil
+}
+
+// releaseStockTx снимает резерв, сделанный под позиции заказа.
+func releaseStockTx(ctx context.Context, q Querier, orderId string) error {
+	_, err := q.ExecContext(ctx, `
+		UPDATE shop.stock s
+		SET reserved = s.reserved - oi.amount
+		FROM (SELECT node_id, size_id, SUM(amount) AS amount
+		      FROM shop.order_items
+		      WHERE order_id = $1
+		        AND size_id IS NOT NULL
+		      GROUP BY node_id, size_id) oi
+		WHERE s.node_id = oi.node_id
+		  AND s.size_id = oi.size_id`,
+		orderId,
+	)
+	return err
+}
+
+// restockTx возвращает на склад единицы, списанные при отгрузке, когда заказ оформлен как возврат.
+// Возвращённый товар сразу снова доступен для заказа; если его нужно сначала проверить,
+// остаток правится вручную через PUT /stock.
+func restockTx(ctx context.Context, q Querier, orderId string) error {
+	_, err := q.ExecContext(ctx, `
+		UPDATE shop.stock s
+		SET quantity = s.quantity + oi.amount
+		FROM (SELECT node_id, size_id, SUM(amount) AS amount
+		      FROM shop.order_items
+		      WHERE order_id = $1
+		        AND size_id IS NOT NULL
+		      GROUP BY node_id, size_id) oi
+		WHERE s.node_id = oi.node_id
+		  AND s.size_id = oi.size_id`,
+		orderId,
+	)
+	return err
+}
+
+// consumeStockTx списывает зарезервированные единицы со склада при отгрузке заказа.
+func consumeStockTx(ctx context.Context, q Querier, orderId string) error {
+	_, err := q.ExecContext(ctx, `
+		UPDATE shop.stock s
+		SET reserved = s.reserved - oi.amount,
+		    quantity = s.quantity - oi.amount
+		FROM (SELECT node_id, size_id, SUM(amount) AS amount
+		      FROM shop.order_items
+		      WHERE order_id = $1
+		        AND size_id IS NOT NULL
+		      GROUP BY node_id, size_id) oi
+		WHERE s.node_id = oi.node_id
+		  AND s.size_id = oi.size_id`,
+		orderId,
+	)
+	return err
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go.uber.org/zap"
+	"shop/internal/model"
+	"shop/pkg/log"
+	"shop/pkg/utils"
+	"strings"
+)
+
+type suggestRepository struct {
+	db txDB
+}
+
+// SuggestRepositoryInterface описывает запросы подсказок поиска. Запросы выполняются с контекстом,
+// чтобы их можно было прервать по истечении времени, отведённого на подсказки.
+type SuggestRepositoryInterface interface {
+	SuggestTitles(ctx context.Context, query string, limit int) ([]model.TitleSuggestion, error)
+	SuggestNodeTypes(ctx context.Context, query string, limit int) ([]model.NodeTypeSuggestion, error)
+	SuggestCharValues(ctx context.Context, query string, limit int) ([]model.CharValueSuggestion, error)
+}
+
+func NewSuggestRepository(db *sql.DB) SuggestRepositoryInterface {
+	return &suggestRepository{db: txDB{db: db}}
+}
+
+// likeEscaper экранирует спецсимволы LIKE, чтобы строка подсказки сравнивалась буквально.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// suggestMatch описывает сопоставление колонки со строкой подсказки ($1 — строка, $2 — она же
+// с экранированными спецсимволами LIKE).
+type suggestMatch struct {
+	column string
+}
+
+// prefix — колонка начинается со строки подсказки.
+func (m suggestMatch) prefix() string {
+	return fmt.Sprintf("%s ILIKE ($2::text || '%%')", m.column)
+}
+
+// condition — начало колонки или одного из её слов совпадает со строкой подсказки,
+// либо колонка похожа на неё по триграммам (опечатки: "мйка" -> "майка").
+func (m suggestMatch) condition() string {
+	return fmt.Sprintf("(%s OR %s ILIKE ('%% ' || $2::text || '%%') OR %s %% $1 OR $1 <%% %s)",
+		m.prefix(), m.column, m.column, m.column)
+}
+
+// score — триграммная похожесть колонки на строку подсказки целиком или на одно из её слов.
+func (m suggestMatch) score() string {
+	return fmt.Sprintf("GREATEST(similarity(%s, $1), word_similarity($1, %s))", m.column, m.column)
+}
+
+// orderBy — сначала совпадения по началу строки, затем по убыванию похожести.
+func (m suggestMatch) orderBy() string {
+	return fmt.Sprintf("%s DESC, score DESC", m.prefix())
+}
+
+// SuggestTitles возвращает названия неудалённых нод, похожие на строку подсказки.
+func (r *suggestRepository) SuggestTitles(ctx context.Context, query string, limit int) ([]model.TitleSuggestion, error) {
+	match := suggestMatch{column: "n.title"}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT n.id, n.title, %s AS score
+		FROM shop.nodes n
+		WHERE n.removed_at IS NULL
+		  AND %s
+		ORDER BY %s, n.id
+		LIMIT $3
+	`, match.score(), match.condition(), match.orderBy()), query, likeEscaper.Replace(query), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	return utils.DecodeRows[model.TitleSuggestion](rows, func(rows *sql.Rows) (model.TitleSuggestion, error) {
+		var suggestion model.TitleSuggestion
+		err := rows.Scan(&suggestion.NodeId, &suggestion.Title, &suggestion.Score)
+		return suggestion, err
+	})
+}
+
+// SuggestNodeTypes возвращает типы нод, похожие на строку подсказки.
+func (r *suggestRepository) SuggestNodeTypes(ctx context.Context, query string, limit int) ([]model.NodeTypeSuggestion, error) {
+	match := suggestMatch{column: "nt.type"}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT nt.id, nt.type, %s AS score
+		FROM shop.node_types nt
+		WHERE %s
+		ORDER BY %s, nt.id
+		LIMIT $3
+	`, match.score(), match.condition(), match.orderBy()), query, likeEscaper.Replace(query), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	return utils.DecodeRows[model.NodeTypeSuggestion](rows, func(rows *sql.Rows) (model.NodeTypeSuggestion, error) {
+		var suggestion model.NodeTypeSuggestion
+		err := rows.Scan(&suggestion.NodeTypeId, &suggestion.Type, &suggestion.Score)
+		return suggestion, err
+	})
+}
+
+// SuggestCharValues возвращает значения по умолчанию видимых характеристик, похожие на строку подсказки.
+func (r *suggestRepository) SuggestCharValues(ctx context.Context, query string, limit int) ([]model.CharValueSuggestion, error) {
+	match := suggestMatch{column: "cdv.value"}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT c.id, c.title, cdv.value, %s AS score
+		FROM shop.char_default_value cdv
+		         JOIN shop.characteristics c ON c.id = cdv.characteristic_id
+		WHERE c.is_visible = true
+		  AND cdv.value IS NOT NULL
+		  AND %s
+		ORDER BY %s, cdv.id
+		LIMIT $3
+	`, match.score(), match.condition(), match.orderBy()), query, likeEscaper.Replace(query), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.FromContext(ctx).Warn("Failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	return utils.DecodeRows[model.CharValueSuggestion](rows, func(rows *sql.Rows) (model.CharValueSuggestion, error) {
+		var suggestion model.CharValueSuggestion
+		err := rows.Scan(&suggestion.CharacteristicId, &suggestion.Characteristic, &suggestion.Value, &suggestion.Score)
+		return suggestion, err
+	})
+}
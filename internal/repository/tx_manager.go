@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go.uber.org/zap"
+	"shop/pkg/log"
+)
+
+// Querier — общий интерфейс *sql.DB и *sql.Tx для выполнения запросов.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// TxManager объединяет вызовы репозиториев в одну транзакцию.
+type TxManager interface {
+	// WithinTx выполняет fn в транзакции: все репозитории, получившие переданный в fn контекст,
+	// работают через неё. Транзакция откатывается, если fn вернула ошибку или запаниковала,
+	// иначе фиксируется. Вложенный вызов присоединяется к уже открытой транзакции.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NewTxManager создаёт TxManager для транзакций над db.
+func NewTxManager(db *sql.DB) TxManager {
+	return txDB{db: db}
+}
+
+// txKey — ключ контекста, под которым хранится открытая транзакция.
+type txKey struct{}
+
+// boundTx — транзакция вместе с подключением, которое её открыло: репозиторий другого
+// подключения (например, второго экземпляра приложения в тестах) её не подхватит.
+type boundTx struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// txDB — подключение репозитория. Запросы выполняются в транзакции из контекста, если её открыл
+// TxManager над тем же *sql.DB, иначе — напрямую в пуле соединений.
+type txDB struct {
+	db *sql.DB
+}
+
+// querier возвращает транзакцию из ctx или само подключение.
+func (d txDB) querier(ctx context.Context) Querier {
+	if current, ok := ctx.Value(txKey{}).(*boundTx); ok && current.db == d.db {
+		return current.tx
+	}
+	return d.db
+}
+
+func (d txDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return d.querier(ctx).ExecContext(ctx, query, args...)
+}
+
+func (d txDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return d.querier(ctx).QueryContext(ctx, query, args...)
+}
+
+func (d txDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return d.querier(ctx).QueryRowContext(ctx, query, args...)
+}
+
+func (d txDB) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if current, ok := ctx.Value(txKey{}).(*boundTx); ok && current.db == d.db {
+		return fn(ctx)
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+
+	defer func() {
+		// Если случится паника — откатываемся
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, &boundTx{db: d.db, tx: tx})); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			log.FromContext(ctx).Error("Failed to rollback transaction", zap.Error(rollbackErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.FromContext(ctx).Error("Failed to commit transaction", zap.Error(err))
+		return err
+	}
+	return nil
+}
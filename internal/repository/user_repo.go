@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go.uber.org/zap"
+	"shop/internal/model"
+	"shop/pkg/app_error"
+	"shop/pkg/log"
+)
+
+type userRepository struct {
+	db txDB
+}
+
+type UserRepositoryInterface interface {
+	CreateUser(ctx context.Context, user *model.UserRow) (int, error)
+	GetUserById(ctx context.Context, id int) (*model.UserRow, error)
+	GetUserByEmail(ctx context.Context, email string) (*model.UserRow, error)
+	ExistsUserWithRole(ctx context.Context, role string) (bool, error)
+	UpdateUserRole(ctx context.Context, id int, role string) error
+}
+
+func NewUserRepository(db *sql.DB) UserRepositoryInterface {
+	return &userRepository{db: txDB{db: db}}
+}
+
+const userSelect = "SELECT id, email, COALESCE(phone, ''), password_hash, role, created_at FROM shop.users"
+
+func (r *userRepository) CreateUser(ctx context.Context, user *model.UserRow) (int, error) {
+	var insertedID int
+	err := r.db.QueryRowContext(ctx,
+		"INSERT INTO shop.users (email, phone, password_hash, role) VALUES ($1, NULLIF($2, ''), $3, $4) RETURNING id",
+		user.Email, user.Phone, user.PasswordHash, user.Role,
+	).Scan(&insertedID)
+
+	if err != nil {
+		if errors.Is(app_error.FromPostgres(err), app_error.ErrConflict) {
+			return 0, app_error.Conflict("User with this email already exists")
+		}
+		return 0, err
+	}
+
+	return insertedID, nil
+}
+
+func (r *userRepository) GetUserById(ctx context.Context, id int) (*model.UserRow, error) {
+	return r.getUser(ctx, userSelect+" WHERE id = $1", id)
+}
+
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*model.UserRow, error) {
+	return r.getUser(ctx, userSelect+" WHERE lower(email) = lower($1)", email)
+}
+
+func (r *userRepository) getUser(ctx context.Context, query string, arg interface{}) (*model.UserRow, error) {
+	var user model.UserRow
+
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(
+		&user.ID,
+		&user.Email,
+		&user.Phone,
+		&user.PasswordHash,
+		&user.Role,
+		&user.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.FromContext(ctx).Warn("User not found")
+			return nil, app_error.NotFound("User not found")
+		}
+		log.FromContext(ctx).Error("Failed to fetch user", zap.Error(err))
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) ExistsUserWithRole(ctx context.Context, role string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM shop.users WHERE role = $1)",
+		role,
+	).Scan(&exists)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to check users by role", zap.String("role", role), zap.Error(err))
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *userRepository) UpdateUserRole(ctx context.Context, id int, role string) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE shop.users SET role = $1 WHERE id = $2", role, id)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to update user role", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		log.FromContext(ctx).Warn("User not found", zap.Int("id", id))
+		return app_error.NotFound("User not found")
+	}
+	return nil
+}
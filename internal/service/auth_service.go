@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"shop/configs/env"
+	"shop/internal/api/dto"
+	"shop/internal/model"
+	"shop/internal/repository"
+	"shop/pkg/app_error"
+	"shop/pkg/log"
+	"shop/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultRefreshTokenTTL — время жизни refresh-токена, если JWT_REFRESH_TTL не задан.
+const defaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+// refreshSecretSize — длина случайной части refresh-токена в байтах.
+const refreshSecretSize = 32
+
+type authService struct {
+	refreshTokenRepo repository.RefreshTokenRepositoryInterface
+	userService      UserServiceInterface
+	jwtService       JWTServiceInterface
+}
+
+type AuthServiceInterface interface {
+	Login(ctx context.Context, dto *dto.LoginRequest) (*model.TokenResponse, error)
+	Refresh(ctx context.Context, dto *dto.RefreshTokenRequest) (*model.TokenResponse, error)
+	Logout(ctx context.Context, dto *dto.RefreshTokenRequest) error
+}
+
+func NewAuthService(
+	refreshTokenRepo repository.RefreshTokenRepositoryInterface,
+	userService UserServiceInterface,
+	jwtService JWTServiceInterface,
+) AuthServiceInterface {
+	return &authService{
+		refreshTokenRepo: refreshTokenRepo,
+		userService:      userService,
+		jwtService:       jwtService,
+	}
+}
+
+// Login проверяет email и пароль пользователя и выпускает пару токенов, открывая новую семью refresh-токенов.
+func (s *authService) Login(ctx context.Context, dto *dto.LoginRequest) (*model.TokenResponse, error) {
+	user, err := s.userService.Authenticate(ctx, dto.Email, dto.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, secret, err := newRefreshToken(user.ID, uuid.New().String())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokenRepo.CreateRefreshToken(ctx, refresh); err != nil {
+		log.FromContext(ctx).Error("Failed to save refresh token", zap.Error(err))
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, user, refresh, secret)
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов. Старый токен после обмена недействителен.
+// Повторное предъявление уже обменянного токена считается кражей: вся семья токенов отзывается.
+func (s *authService) Refresh(ctx context.Context, dto *dto.RefreshTokenRequest) (*model.TokenResponse, error) {
+	current, err := s.verifyRefreshToken(ctx, dto.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.RotatedAt != nil {
+		return nil, s.revokeReusedFamily(ctx, current)
+	}
+
+	user, err := s.userService.GetUserById(ctx, current.UserId)
+	if err != nil {
+		return nil, invalidRefreshToken()
+	}
+
+	next, secret, err := newRefreshToken(user.ID, current.FamilyId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.refreshTokenRepo.RotateRefreshToken(ctx, current.ID, next)
+	if err != nil {
+		// Токен успели обменять параллельным запросом
+		if errors.Is(err, repository.ErrRefreshTokenReused) {
+			return nil, s.revokeReusedFamily(ctx, current)
+		}
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, user, next, secret)
+}
+
+// Logout отзывает семью, к которой принадлежит refresh-токен. Access-токен остаётся действительным до истечения срока.
+func (s *authService) Logout(ctx context.Context, dto *dto.RefreshTokenRequest) error {
+	current, err := s.verifyRefreshToken(ctx, dto.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	return s.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, current.FamilyId)
+}
+
+// issueTokens выпускает access-токен и собирает ответ вместе с уже сохранённым refresh-токеном.
+func (s *authService) issueTokens(ctx context.Context, user *model.UserRow, refresh *model.RefreshTokenRow, secret string) (*model.TokenResponse, error) {
+	token, ttl, err := s.jwtService.GenerateToken(strconv.Itoa(user.ID), user.Role)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to generate access token", zap.Error(err))
+		return nil, err
+	}
+
+	return &model.TokenResponse{
+		AccessToken:      token,
+		TokenType:        "Bearer",
+		ExpiresIn:        int(ttl.Seconds()),
+		RefreshToken:     refresh.ID + "." + secret,
+		RefreshExpiresIn: int(time.Until(refresh.ExpiresAt).Seconds()),
+	}, nil
+}
+
+// newRefreshToken генерирует refresh-токен семьи familyId. Возвращает запись для БД (с хешем секрета)
+// и сам секрет, который отдаётся клиенту один раз.
+func newRefreshToken(userId int, familyId string) (*model.RefreshTokenRow, string, error) {
+	raw := make([]byte, refreshSecretSize)
+	if _, err := rand.Read(raw); err != nil {
+		log.Error("Failed to generate refresh token", zap.Error(err))
+		return nil, "", err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(raw)
+
+	hash, err := utils.HashData(secret, passwordHashCost)
+	if err != nil {
+		log.Error("Failed to hash refresh token", zap.Error(err))
+		return nil, "", err
+	}
+
+	return &model.RefreshTokenRow{
+		ID:        uuid.New().String(),
+		FamilyId:  familyId,
+		UserId:    userId,
+		TokenHash: hash,
+		// В колонке TIMESTAMP без часового пояса храним UTC
+		ExpiresAt: time.Now().UTC().Add(refreshTokenTTL()),
+	}, secret, nil
+}
+
+// verifyRefreshToken разбирает токен формата "<id>.<secret>", сверяет секрет с хешем и проверяет,
+// что токен не отозван и не истёк. Обменянный токен возвращается как есть — решение принимает вызывающий.
+func (s *authService) verifyRefreshToken(ctx context.Context, token string) (*model.RefreshTokenRow, error) {
+	id, secret, ok := strings.Cut(token, ".")
+	if !ok || secret == "" {
+		return nil, invalidRefreshToken()
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, invalidRefreshToken()
+	}
+
+	row, err := s.refreshTokenRepo.GetRefreshTokenById(ctx, id)
+	if err != nil {
+		return nil, invalidRefreshToken()
+	}
+
+	match, err := utils.CompareHashes(secret, row.TokenHash)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to compare refresh token hash", zap.Error(err))
+		return nil, err
+	}
+	if !match {
+		log.FromContext(ctx).Warn("Refresh token secret mismatch", zap.String("id", id))
+		return nil, invalidRefreshToken()
+	}
+
+	if row.RevokedAt != nil || time.Now().After(row.ExpiresAt) {
+		return nil, invalidRefreshToken()
+	}
+
+	return row, nil
+}
+
+// revokeReusedFamily отзывает семью повторно предъявленного токена и возвращает ошибку 401.
+func (s *authService) revokeReusedFamily(ctx context.Context, token *model.RefreshTokenRow) error {
+	log.FromContext(ctx).Warn("Refresh token reuse detected, revoking family",
+		zap.String("tokenId", token.ID),
+		zap.String("familyId", token.FamilyId),
+		zap.Int("userId", token.UserId))
+
+	if err := s.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, token.FamilyId); err != nil {
+		return err
+	}
+	return invalidRefreshToken()
+}
+
+func invalidRefreshToken() error {
+	return app_error.Unauthorized("Invalid or expired refresh token")
+}
+
+// refreshTokenTTL читает время жизни refresh-токена из JWT_REFRESH_TTL (например, "720h").
+func refreshTokenTTL() time.Duration {
+	raw := env.GetEnv("JWT_REFRESH_TTL", "")
+	if raw == "" {
+		return defaultRefreshTokenTTL
+	}
+
+	ttl, err := time.ParseDuration(raw)
+	if err != nil || ttl <= 0 {
+		log.Warn(fmt.Sprintf("Invalid JWT_REFRESH_TTL, using default %s", defaultRefreshTokenTTL), zap.String("value", raw))
+		return defaultRefreshTokenTTL
+	}
+	return ttl
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"shop/internal/api/dto"
+	"shop/internal/model"
+	"shop/internal/repository"
+	"shop/pkg/app_error"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRefreshTokenRepo хранит refresh-токены в памяти и повторяет условия RotateRefreshToken из БД.
+type fakeRefreshTokenRepo struct {
+	mu     sync.Mutex
+	tokens map[string]*model.RefreshTokenRow
+	// rotateErr, если задана, возвращается из RotateRefreshToken вместо обмена
+	rotateErr error
+}
+
+func newFakeRefreshTokenRepo() *fakeRefreshTokenRepo {
+	return &fakeRefreshTokenRepo{tokens: make(map[string]*model.RefreshTokenRow)}
+}
+
+func (r *fakeRefreshTokenRepo) CreateRefreshToken(_ context.Context, token *model.RefreshTokenRow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row := *token
+	r.tokens[token.ID] = &row
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) GetRefreshTokenById(_ context.Context, id string) (*model.RefreshTokenRow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.tokens[id]
+	if !ok {
+		return nil, app_error.NotFound("Refresh token not found")
+	}
+	copied := *row
+	return &copied, nil
+}
+
+func (r *fakeRefreshTokenRepo) RotateRefreshToken(ctx context.Context, oldId string, newToken *model.RefreshTokenRow) error {
+	if r.rotateErr != nil {
+		return r.rotateErr
+	}
+
+	r.mu.Lock()
+	old := r.tokens[oldId]
+	if old.RotatedAt != nil || old.RevokedAt != nil {
+		r.mu.Unlock()
+		return repository.ErrRefreshTokenReused
+	}
+	now := time.Now()
+	old.RotatedAt = &now
+	r.mu.Unlock()
+
+	return r.CreateRefreshToken(ctx, newToken)
+}
+
+func (r *fakeRefreshTokenRepo) RevokeRefreshTokenFamily(_ context.Context, familyId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, row := range r.tokens {
+		if row.FamilyId == familyId && row.RevokedAt == nil {
+			row.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// revoked сообщает, отозваны ли все токены семьи familyId.
+func (r *fakeRefreshTokenRepo) revoked(familyId string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, row := range r.tokens {
+		if row.FamilyId == familyId && row.RevokedAt == nil {
+			return false
+		}
+	}
+	return true
+}
+
+type fakeUserService struct {
+	UserServiceInterface
+}
+
+func (fakeUserService) GetUserById(_ context.Context, id int) (*model.UserRow, error) {
+	return &model.UserRow{ID: id, Role: model.RoleCustomer}, nil
+}
+
+type fakeJWTService struct {
+	JWTServiceInterface
+}
+
+func (fakeJWTService) GenerateToken(string, string) (string, time.Duration, error) {
+	return "access", time.Hour, nil
+}
+
+// loginToken сохраняет в repo refresh-токен новой семьи и возвращает его в виде, который получает клиент.
+func loginToken(t *testing.T, repo *fakeRefreshTokenRepo) (string, string) {
+	t.Helper()
+
+	row, secret, err := newRefreshToken(7, "family-1")
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateRefreshToken(context.Background(), row))
+	return row.ID + "." + secret, row.FamilyId
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	repo := newFakeRefreshTokenRepo()
+	serv := NewAuthService(repo, fakeUserService{}, fakeJWTService{})
+	token, familyId := loginToken(t, repo)
+
+	resp, err := serv.Refresh(context.Background(), &dto.RefreshTokenRequest{RefreshToken: token})
+	require.NoError(t, err)
+	assert.Equal(t, "access", resp.AccessToken)
+	assert.NotEqual(t, token, resp.RefreshToken)
+	assert.False(t, repo.revoked(familyId))
+
+	// Новый токен снова обменивается
+	_, err = serv.Refresh(context.Background(), &dto.RefreshTokenRequest{RefreshToken: resp.RefreshToken})
+	assert.NoError(t, err)
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	repo := newFakeRefreshTokenRepo()
+	serv := NewAuthService(repo, fakeUserService{}, fakeJWTService{})
+	token, familyId := loginToken(t, repo)
+
+	resp, err := serv.Refresh(context.Background(), &dto.RefreshTokenRequest{RefreshToken: token})
+	require.NoError(t, err)
+
+	// Повторное предъявление обменянного токена отзывает всю семью,
+	// в том числе токен, выданный легитимному клиенту
+	_, err = serv.Refresh(context.Background(), &dto.RefreshTokenRequest{RefreshToken: token})
+	assert.True(t, errors.Is(err, app_error.ErrUnauthorized))
+	assert.True(t, repo.revoked(familyId))
+
+	_, err = serv.Refresh(context.Background(), &dto.RefreshTokenRequest{RefreshToken: resp.RefreshToken})
+	assert.True(t, errors.Is(err, app_error.ErrUnauthorized))
+}
+
+func TestRefreshConcurrentRotationRevokesFamily(t *testing.T) {
+	repo := newFakeRefreshTokenRepo()
+	serv := NewAuthService(repo, fakeUserService{}, fakeJWTService{})
+	token, familyId := loginToken(t, repo)
+
+	// Токен обменян параллельным запросом между проверкой и ротацией
+	repo.rotateErr = repository.ErrRefreshTokenReused
+
+	_, err := serv.Refresh(context.Background(), &dto.RefreshTokenRequest{RefreshToken: token})
+	assert.True(t, errors.Is(err, app_error.ErrUnauthorized))
+	assert.True(t, repo.revoked(familyId))
+}
+
+func TestRefreshRejectsInvalidTokens(t *testing.T) {
+	repo := newFakeRefreshTokenRepo()
+	serv := NewAuthService(repo, fakeUserService{}, fakeJWTService{})
+	token, familyId := loginToken(t, repo)
+	id, _, _ := strings.Cut(token, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"no secret", id},
+		{"empty secret", id + "."},
+		{"not uuid", "abc.secret"},
+		{"unknown id", "3f2b8c1e-9d4a-4e6b-8f7a-1c2d3e4f5a6b.secret"},
+		{"wrong secret", id + ".wrong"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := serv.Refresh(context.Background(), &dto.RefreshTokenRequest{RefreshToken: tt.token})
+			assert.True(t, errors.Is(err, app_error.ErrUnauthorized))
+		})
+	}
+
+	// Неверный секрет не считается повторным использованием
+	assert.False(t, repo.revoked(familyId))
+}
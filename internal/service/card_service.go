@@ -1,35 +1,62 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"go.uber.org/zap"
 	"shop/internal/api/dto"
 	"shop/internal/model"
 	"shop/internal/repository"
+	"shop/pkg/app_error"
 	"shop/pkg/log"
+	"shop/pkg/metrics"
 	"shop/pkg/utils"
+	"sort"
 )
 
-type cardService struct{}
+type cardService struct {
+	txManager          repository.TxManager
+	cardRepo           repository.CardRepositoryInterface
+	characteristicRepo repository.CharacteristicRepositoryInterface
+	nodeRepo           repository.NodeRepositoryInterface
+	nodeTypeRepo       repository.NodeTypeRepositoryInterface
+}
 
 type CardServiceInterface interface {
-	GetCardById(id int) (*model.CardResponse, error)
-	GetAllCards(pageNumber, pageSize int, filters *[]model.CardFilter) (*model.Paginate[model.CardResponse], error)
-	CreateCard(dto *dto.CreateCardDTO) (*model.CardResponse, error)
-	GetCardsByVector(dto *dto.GetCardsByVectorDTO) (*[]model.CardResponse, error)
+	GetCardById(ctx context.Context, id int, includeRemoved bool) (*model.CardResponse, error)
+	GetAllCards(ctx context.Context, pageNumber, pageSize int, cursor *model.CardCursor, filter *model.CardFilter, sort string, includeRemoved bool) (*model.Paginate[model.CardResponse], error)
+	CreateCard(ctx context.Context, dto *dto.CreateCardDTO) (*model.CardResponse, error)
+	GetCardFacets(ctx context.Context, filter *model.CardFilter, includeRemoved bool, priceStep int) (*model.CardFacetsResponse, error)
+	GetCardsByVector(ctx context.Context, dto *dto.GetCardsByVectorDTO, includeRemoved bool) (*[]model.CardResponse, error)
+	UpdateCard(ctx context.Context, id int, dto *dto.UpdateCardDTO) (*model.CardResponse, error)
+	PatchCard(ctx context.Context, id int, dto *dto.PatchCardDTO) (*model.CardResponse, error)
 }
 
-func NewCardService() CardServiceInterface {
-	return &cardService{}
+func NewCardService(
+	txManager repository.TxManager,
+	cardRepo repository.CardRepositoryInterface,
+	characteristicRepo repository.CharacteristicRepositoryInterface,
+	nodeRepo repository.NodeRepositoryInterface,
+	nodeTypeRepo repository.NodeTypeRepositoryInterface,
+) CardServiceInterface {
+	return &cardService{
+		txManager:          txManager,
+		cardRepo:           cardRepo,
+		characteristicRepo: characteristicRepo,
+		nodeRepo:           nodeRepo,
+		nodeTypeRepo:       nodeTypeRepo,
+	}
 }
 
-var CardService = NewCardService()
-
-func (s *cardService) GetCardById(id int) (*model.CardResponse, error) {
-	card, err := repository.CardRepo.GetCardById(id)
+// GetCardById возвращает карточку. Удалённая нода возвращается только при includeRemoved.
+func (s *cardService) GetCardById(ctx context.Context, id int, includeRemoved bool) (*model.CardResponse, error) {
+	card, err := s.cardRepo.GetCardById(ctx, id, includeRemoved)
 	if err != nil {
 		return nil, err
 	}
+	if len(*card) == 0 {
+		return nil, app_error.NotFound("Card not found")
+	}
 
 	result, err := model.MapperCardResponse(card)
 	if err != nil {
@@ -40,16 +67,25 @@ func (s *cardService) GetCardById(id int) (*model.CardResponse, error) {
 	return el, nil
 }
 
-func (s *cardService) GetAllCards(pageNumber, pageSize int, filters *[]model.CardFilter) (*model.Paginate[model.CardResponse], error) {
-	cards, totalCount, err := repository.CardRepo.GetAllCards(pageNumber, pageSize, filters)
+// GetAllCards возвращает страницу карточек. При переданном cursor страница строится по курсору,
+// иначе по номеру страницы; в обоих случаях в ответ добавляется курсор следующей страницы.
+func (s *cardService) GetAllCards(ctx context.Context, pageNumber, pageSize int, cursor *model.CardCursor, filter *model.CardFilter, sort string, includeRemoved bool) (*model.Paginate[model.CardResponse], error) {
+	cards, totalCount, next, err := s.cardRepo.GetAllCards(ctx, pageNumber, pageSize, cursor, filter, sort, includeRemoved)
 	if err != nil {
-		log.Error("Failed to fetch cards", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch cards", zap.Error(err))
 		return nil, err
 	}
 
-	mappedCards, err := model.MapperCardResponse(cards)
-	if err != nil {
-		return nil, err
+	mappedCards := make([]model.CardResponse, 0)
+	if len(*cards) > 0 {
+		mappedCards, err = model.MapperCardResponse(cards)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Пустой результат поиска считаем только для первой страницы, чтобы не учитывать конец списка
+	if len(*cards) == 0 && filter != nil && filter.Query != "" && cursor == nil && pageNumber == 1 {
+		metrics.SearchZeroResults.WithLabelValues("cards").Inc()
 	}
 
 	result := &model.Paginate[model.CardResponse]{
@@ -60,35 +96,265 @@ func (s *cardService) GetAllCards(pageNumber, pageSize int, filters *[]model.Car
 		Items:          mappedCards,
 	}
 
+	if next != nil {
+		nextCursor, err := utils.EncodeCursor(next)
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to encode cards cursor", zap.Error(err))
+			return nil, err
+		}
+		result.NextCursor = &nextCursor
+	}
+
 	return result, nil
 }
 
-func (s *cardService) GetCardsByVector(dto *dto.GetCardsByVectorDTO) (*[]model.CardResponse, error) {
-	cards, err := repository.CardRepo.FindByVectorSearch(dto.Text, dto.Limit)
+// GetCardFacets считает фасеты для текущего фильтра списка карточек: количество карточек
+// по значениям характеристик, типам нод и ценовым интервалам шириной priceStep.
+//
+// Каждый фасет считается без собственного условия фильтра: значения характеристики — без выбранных
+// значений этой характеристики, типы нод — без nodeTypeId, цены — без priceMin/priceMax.
+// Так видно, сколько товаров будет, если выбрать другой вариант того же фасета.
+func (s *cardService) GetCardFacets(ctx context.Context, filter *model.CardFilter, includeRemoved bool, priceStep int) (*model.CardFacetsResponse, error) {
+	if filter == nil {
+		filter = &model.CardFilter{Currency: model.CurrencyByn}
+	}
+	if priceStep < 1 {
+		priceStep = model.DefaultFacetPriceStep
+	}
+
+	total, err := s.cardRepo.CountCards(ctx, filter, includeRemoved)
 	if err != nil {
-		log.Error("Failed to fetch cards", zap.Error(err))
 		return nil, err
 	}
 
-	mappedCards, err := model.MapperCardResponse(cards)
+	characteristics, err := s.getCharacteristicFacets(ctx, filter, includeRemoved)
 	if err != nil {
 		return nil, err
 	}
 
-	return &mappedCards, nil
+	nodeTypes, err := s.cardRepo.GetNodeTypeFacets(ctx, filter.WithoutNodeTypes(), includeRemoved)
+	if err != nil {
+		return nil, err
+	}
+
+	priceBuckets, err := s.cardRepo.GetPriceFacets(ctx, filter.WithoutPrice(), includeRemoved, priceStep)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CardFacetsResponse{
+		Total:           total,
+		Currency:        filter.Currency,
+		PriceStep:       priceStep,
+		Characteristics: characteristics,
+		NodeTypes:       nodeTypes,
+		PriceBuckets:    priceBuckets,
+	}, nil
+}
+
+// getCharacteristicFacets собирает фасеты характеристик. Набор характеристик и их значения по умолчанию
+// берутся из GetCharFilters (как для /characteristics/filters), счётчики — из GetCharValueCounts.
+// Значения по умолчанию, которых нет ни у одной подходящей карточки, возвращаются с нулевым счётчиком.
+func (s *cardService) getCharacteristicFacets(ctx context.Context, filter *model.CardFilter, includeRemoved bool) ([]model.CharacteristicFacet, error) {
+	nodeTypeId := 0
+	if len(filter.NodeTypeIds) == 1 {
+		nodeTypeId = filter.NodeTypeIds[0]
+	}
+
+	available, err := s.characteristicRepo.GetCharFilters(ctx, nodeTypeId, includeRemoved)
+	if err != nil {
+		return nil, err
+	}
+
+	// Группируем значения по умолчанию по характеристикам, сохраняя порядок по id
+	var (
+		facets   []model.CharacteristicFacet
+		defaults = make(map[int][]string)
+		known    = make(map[int]struct{})
+	)
+	for _, row := range *available {
+		if _, exists := known[row.CharacteristicId]; !exists {
+			known[row.CharacteristicId] = struct{}{}
+			facets = append(facets, model.CharacteristicFacet{
+				CharacteristicId: row.CharacteristicId,
+				Title:            row.Title,
+				Description:      row.Description,
+			})
+		}
+		if row.Value != nil {
+			defaults[row.CharacteristicId] = append(defaults[row.CharacteristicId], *row.Value)
+		}
+	}
+
+	rows, err := s.characteristicRepo.GetCharValueCounts(ctx, filter, includeRemoved, 0)
+	if err != nil {
+		return nil, err
+	}
+	counts := groupCharValueCounts(rows)
+
+	// Характеристики с выбранными значениями пересчитываются без их собственного условия
+	for _, charFilter := range filter.Characteristics {
+		charId := charFilter.CharacteristicId
+		if _, exists := known[charId]; charFilter.Negate || !exists {
+			continue
+		}
+
+		rows, err := s.characteristicRepo.GetCharValueCounts(ctx,
+			filter.WithoutCharacteristic(charId), includeRemoved, charId)
+		if err != nil {
+			return nil, err
+		}
+		counts[charId] = groupCharValueCounts(rows)[charId]
+	}
+
+	result := make([]model.CharacteristicFacet, 0, len(facets))
+	for _, facet := range facets {
+		valueCounts := counts[facet.CharacteristicId]
+
+		facet.Values = make([]model.FacetValue, 0, len(valueCounts))
+		for value, count := range valueCounts {
+			facet.Values = append(facet.Values, model.FacetValue{Value: value, Count: count})
+		}
+		for _, value := range defaults[facet.CharacteristicId] {
+			if _, counted := valueCounts[value]; !counted {
+				facet.Values = append(facet.Values, model.FacetValue{Value: value})
+			}
+		}
+		if len(facet.Values) == 0 {
+			continue
+		}
+
+		// Сначала самые частые значения, при равенстве — по алфавиту
+		sort.Slice(facet.Values, func(i, j int) bool {
+			if facet.Values[i].Count != facet.Values[j].Count {
+				return facet.Values[i].Count > facet.Values[j].Count
+			}
+			return facet.Values[i].Value < facet.Values[j].Value
+		})
+
+		result = append(result, facet)
+	}
+
+	return result, nil
+}
+
+// groupCharValueCounts раскладывает счётчики значений по характеристикам.
+func groupCharValueCounts(rows []model.CharValueCountRow) map[int]map[string]int {
+	counts := make(map[int]map[string]int)
+	for _, row := range rows {
+		if counts[row.CharacteristicId] == nil {
+			counts[row.CharacteristicId] = make(map[string]int)
+		}
+		counts[row.CharacteristicId][row.Value] = row.Count
+	}
+	return counts
 }
 
-func (s *cardService) CreateCard(dto *dto.CreateCardDTO) (*model.CardResponse, error) {
-	newID, err := repository.CardRepo.CreateCard(dto)
+// GetCardsByVector возвращает до dto.Limit самых релевантных карточек по полнотекстовому поиску.
+// Это первая страница списка карточек с q=dto.Text и сортировкой relevance.
+func (s *cardService) GetCardsByVector(ctx context.Context, dto *dto.GetCardsByVectorDTO, includeRemoved bool) (*[]model.CardResponse, error) {
+	filter := &model.CardFilter{Query: dto.Text, Currency: model.CurrencyByn}
+
+	page, err := s.GetAllCards(ctx, 1, dto.Limit, nil, filter, model.CardSortRelevance, includeRemoved)
 	if err != nil {
-		log.Error("Failed to fetch card, after creating", zap.Error(err))
-		return nil, errors.New("failed to create card")
+		return nil, err
 	}
 
-	newCard, err := s.GetCardById(newID)
+	return &page.Items, nil
+}
+
+func (s *cardService) CreateCard(ctx context.Context, dto *dto.CreateCardDTO) (*model.CardResponse, error) {
+	var newCard *model.CardResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		newID, err := s.cardRepo.CreateCard(ctx, dto)
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to create card", zap.Error(err))
+			return err
+		}
+
+		newCard, err = s.GetCardById(ctx, newID, true)
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to fetch card, after creating", zap.Error(err))
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		log.Error("Failed to fetch card, after creating", zap.Error(err))
 		return nil, err
 	}
 	return newCard, nil
 }
+
+func (s *cardService) UpdateCard(ctx context.Context, id int, dto *dto.UpdateCardDTO) (*model.CardResponse, error) {
+	var updatedCard *model.CardResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.ensureCardEditable(ctx, id); err != nil {
+			return err
+		}
+
+		if _, err := s.nodeTypeRepo.GetNodeTypeById(ctx, dto.NodeTypeId); err != nil {
+			log.FromContext(ctx).Error("NodeTypeId not found", zap.Error(err))
+			return err
+		}
+
+		if err := s.cardRepo.UpdateCard(ctx, id, dto); err != nil {
+			log.FromContext(ctx).Error("Failed to update card", zap.Int("id", id), zap.Error(err))
+			return err
+		}
+
+		var err error
+		updatedCard, err = s.GetCardById(ctx, id, true)
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to fetch card, after updating", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updatedCard, nil
+}
+
+// ensureCardEditable проверяет, что карточка существует и не удалена: удалённые ноды
+// не редактируются, пока их не восстановят.
+func (s *cardService) ensureCardEditable(ctx context.Context, id int) error {
+	node, err := s.nodeRepo.GetNodeById(ctx, id)
+	if errors.Is(err, app_error.ErrNotFound) || (err == nil && node.RemovedAt != nil) {
+		return app_error.NotFound("Card not found")
+	}
+	return err
+}
+
+func (s *cardService) PatchCard(ctx context.Context, id int, dto *dto.PatchCardDTO) (*model.CardResponse, error) {
+	var patchedCard *model.CardResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.ensureCardEditable(ctx, id); err != nil {
+			return err
+		}
+
+		if dto.NodeTypeId != nil {
+			if _, err := s.nodeTypeRepo.GetNodeTypeById(ctx, *dto.NodeTypeId); err != nil {
+				log.FromContext(ctx).Error("NodeTypeId not found", zap.Error(err))
+				return err
+			}
+		}
+
+		if err := s.cardRepo.PatchCard(ctx, id, dto); err != nil {
+			log.FromContext(ctx).Error("Failed to patch card", zap.Int("id", id), zap.Error(err))
+			return err
+		}
+
+		var err error
+		patchedCard, err = s.GetCardById(ctx, id, true)
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to fetch card, after patching", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return patchedCard, nil
+}
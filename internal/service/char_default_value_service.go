@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"go.uber.org/zap"
 	"shop/internal/api/dto"
 	"shop/internal/model"
@@ -9,26 +10,26 @@ import (
 	"shop/pkg/utils"
 )
 
-type charDefaultValueService struct{}
+type charDefaultValueService struct {
+	charDefaultValueRepo repository.CharDefaultValueInterface
+}
 
 type CharDefaultValueServiceInterface interface {
-	GetAllDefValue(pageNumber, pageSize int) (*model.Paginate[model.CharDefaultValue], error)
-	GetDefValueById(id int) (*[]model.CharDefaultValue, error)
-	CreateDefValue(size *dto.CreateCharDefValueRequest) (*model.CharDefaultValueRow, error)
-	UpdateDefValue(size *dto.UpdateCharDefValueRequest) (*model.CharDefaultValueRow, error)
-	DeleteDefValueById(id int) error
+	GetAllDefValue(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[model.CharDefaultValue], error)
+	GetDefValueById(ctx context.Context, id int) (*[]model.CharDefaultValue, error)
+	CreateDefValue(ctx context.Context, size *dto.CreateCharDefValueRequest) (*model.CharDefaultValueRow, error)
+	UpdateDefValue(ctx context.Context, size *dto.UpdateCharDefValueRequest) (*model.CharDefaultValueRow, error)
+	DeleteDefValueById(ctx context.Context, id int) error
 }
 
-func NewCharDefaultValueService() CharDefaultValueServiceInterface {
-	return &charDefaultValueService{}
+func NewCharDefaultValueService(charDefaultValueRepo repository.CharDefaultValueInterface) CharDefaultValueServiceInterface {
+	return &charDefaultValueService{charDefaultValueRepo: charDefaultValueRepo}
 }
 
-var CharDefaultValueService = NewCharDefaultValueService()
-
-func (s *charDefaultValueService) GetAllDefValue(pageNumber, pageSize int) (*model.Paginate[model.CharDefaultValue], error) {
-	defValues, totalCount, err := repository.CharDefaultValueRepo.GetAllDefaultValues(pageNumber, pageSize)
+func (s *charDefaultValueService) GetAllDefValue(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[model.CharDefaultValue], error) {
+	defValues, totalCount, err := s.charDefaultValueRepo.GetAllDefaultValues(ctx, pageNumber, pageSize)
 	if err != nil {
-		log.Error("Failed to fetch char_default_value", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch char_default_value", zap.Error(err))
 		return nil, err
 	}
 
@@ -44,24 +45,24 @@ func (s *charDefaultValueService) GetAllDefValue(pageNumber, pageSize int) (*mod
 	return result, nil
 }
 
-func (s *charDefaultValueService) GetDefValueById(id int) (*[]model.CharDefaultValue, error) {
-	defValues, err := repository.CharDefaultValueRepo.GetFullDefaultValueById(id)
+func (s *charDefaultValueService) GetDefValueById(ctx context.Context, id int) (*[]model.CharDefaultValue, error) {
+	defValues, err := s.charDefaultValueRepo.GetFullDefaultValueById(ctx, id)
 	if err != nil {
-		log.Error("Failed to fetch char_default_value", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch char_default_value", zap.Error(err))
 		return nil, err
 	}
 	return defValues, nil
 }
 
-func (s *charDefaultValueService) CreateDefValue(dto *dto.CreateCharDefValueRequest) (*model.CharDefaultValueRow, error) {
+func (s *charDefaultValueService) CreateDefValue(ctx context.Context, dto *dto.CreateCharDefValueRequest) (*model.CharDefaultValueRow, error) {
 
-	createdID, err := repository.CharDefaultValueRepo.CreateDefaultValue(dto)
+	createdID, err := s.charDefaultValueRepo.CreateDefaultValue(ctx, dto)
 	if err != nil {
-		log.Error("Failed to create char_default_value", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to create char_default_value", zap.Error(err))
 		return nil, err
 	}
 
-	nodeType, err := repository.CharDefaultValueRepo.GetDefaultValueById(createdID)
+	nodeType, err := s.charDefaultValueRepo.GetDefaultValueById(ctx, createdID)
 	if err != nil {
 		return nil, err
 	}
@@ -69,18 +70,18 @@ func (s *charDefaultValueService) CreateDefValue(dto *dto.CreateCharDefValueRequ
 	return nodeType, nil
 }
 
-func (s *charDefaultValueService) UpdateDefValue(dto *dto.UpdateCharDefValueRequest) (*model.CharDefaultValueRow, error) {
-	_, err := repository.CharDefaultValueRepo.GetDefaultValueById(dto.ID)
+func (s *charDefaultValueService) UpdateDefValue(ctx context.Context, dto *dto.UpdateCharDefValueRequest) (*model.CharDefaultValueRow, error) {
+	_, err := s.charDefaultValueRepo.GetDefaultValueById(ctx, dto.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := repository.CharDefaultValueRepo.UpdateDefaultValue(dto); err != nil {
-		log.Error("Failed to update char_default_value", zap.Error(err))
+	if err := s.charDefaultValueRepo.UpdateDefaultValue(ctx, dto); err != nil {
+		log.FromContext(ctx).Error("Failed to update char_default_value", zap.Error(err))
 		return nil, err
 	}
 
-	updatedRow, err := repository.CharDefaultValueRepo.GetDefaultValueById(dto.ID)
+	updatedRow, err := s.charDefaultValueRepo.GetDefaultValueById(ctx, dto.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -88,9 +89,9 @@ func (s *charDefaultValueService) UpdateDefValue(dto *dto.UpdateCharDefValueRequ
 	return updatedRow, nil
 }
 
-func (s *charDefaultValueService) DeleteDefValueById(id int) error {
-	if err := repository.CharDefaultValueRepo.DeleteDefaultValueById(id); err != nil {
-		log.Error("Failed to delete char_default_value", zap.Error(err))
+func (s *charDefaultValueService) DeleteDefValueById(ctx context.Context, id int) error {
+	if err := s.charDefaultValueRepo.DeleteDefaultValueById(ctx, id); err != nil {
+		log.FromContext(ctx).Error("Failed to delete char_default_value", zap.Error(err))
 		return err
 	}
 	return nil
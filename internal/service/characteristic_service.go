@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"go.uber.org/zap"
 	"shop/internal/api/dto"
 	"shop/internal/model"
@@ -10,26 +11,27 @@ import (
 	"sort"
 )
 
-type characteristicService struct{}
+type characteristicService struct {
+	txManager          repository.TxManager
+	characteristicRepo repository.CharacteristicRepositoryInterface
+}
 
 type CharacteristicServiceInterface interface {
-	GetAllCharacteristic(pageNumber, pageSize int) (*model.Paginate[model.CharacteristicRow], error)
-	GetCharForFilters(nodeTypeId int) (*[]model.CharFilterResponse, error)
-	CreateCharacteristic(size *dto.CreateCharacteristicRequest) (*model.CharacteristicRow, error)
-	UpdateCharacteristic(size *dto.UpdateCharacteristicRequest) (*model.CharacteristicRow, error)
-	DeleteCharacteristic(id int) error
+	GetAllCharacteristic(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[model.CharacteristicRow], error)
+	GetCharForFilters(ctx context.Context, nodeTypeId int, includeRemoved bool) (*[]model.CharFilterResponse, error)
+	CreateCharacteristic(ctx context.Context, size *dto.CreateCharacteristicRequest) (*model.CharacteristicRow, error)
+	UpdateCharacteristic(ctx context.Context, size *dto.UpdateCharacteristicRequest) (*model.CharacteristicRow, error)
+	DeleteCharacteristic(ctx context.Context, id int) error
 }
 
-func NewCharacteristicService() CharacteristicServiceInterface {
-	return &characteristicService{}
+func NewCharacteristicService(txManager repository.TxManager, characteristicRepo repository.CharacteristicRepositoryInterface) CharacteristicServiceInterface {
+	return &characteristicService{txManager: txManager, characteristicRepo: characteristicRepo}
 }
 
-var CharacteristicService = NewCharacteristicService()
-
-func (s *characteristicService) GetAllCharacteristic(pageNumber, pageSize int) (*model.Paginate[model.CharacteristicRow], error) {
-	characteristics, totalCount, err := repository.CharacteristicRepo.GetAllCharacteristics(pageNumber, pageSize)
+func (s *characteristicService) GetAllCharacteristic(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[model.CharacteristicRow], error) {
+	characteristics, totalCount, err := s.characteristicRepo.GetAllCharacteristics(ctx, pageNumber, pageSize)
 	if err != nil {
-		log.Error("Failed to fetch characteristics", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch characteristics", zap.Error(err))
 		return nil, err
 	}
 
@@ -45,15 +47,15 @@ func (s *characteristicService) GetAllCharacteristic(pageNumber, pageSize int) (
 	return result, nil
 }
 
-func (s *characteristicService) CreateCharacteristic(dto *dto.CreateCharacteristicRequest) (*model.CharacteristicRow, error) {
+func (s *characteristicService) CreateCharacteristic(ctx context.Context, dto *dto.CreateCharacteristicRequest) (*model.CharacteristicRow, error) {
 
-	createdID, err := repository.CharacteristicRepo.CreateCharacteristics(dto)
+	createdID, err := s.characteristicRepo.CreateCharacteristics(ctx, dto)
 	if err != nil {
-		log.Error("Failed to create characteristic", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to create characteristic", zap.Error(err))
 		return nil, err
 	}
 
-	characteristic, err := repository.CharacteristicRepo.GetCharacteristicsById(createdID)
+	characteristic, err := s.characteristicRepo.GetCharacteristicsById(ctx, createdID)
 	if err != nil {
 		return nil, err
 	}
@@ -61,12 +63,7 @@ func (s *characteristicService) CreateCharacteristic(dto *dto.CreateCharacterist
 	return characteristic, nil
 }
 
-func (s *characteristicService) UpdateCharacteristic(dto *dto.UpdateCharacteristicRequest) (*model.CharacteristicRow, error) {
-	_, err := repository.CharacteristicRepo.GetCharacteristicsById(dto.ID)
-	if err != nil {
-		return nil, err
-	}
-
+func (s *characteristicService) UpdateCharacteristic(ctx context.Context, dto *dto.UpdateCharacteristicRequest) (*model.CharacteristicRow, error) {
 	row := model.CharacteristicRow{
 		ID:          dto.ID,
 		Title:       dto.Title,
@@ -74,24 +71,34 @@ func (s *characteristicService) UpdateCharacteristic(dto *dto.UpdateCharacterist
 		IsVisible:   dto.IsVisible,
 	}
 
-	if err := repository.CharacteristicRepo.UpdateCharacteristics(&row); err != nil {
-		log.Error("Failed to update size", zap.Error(err))
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.characteristicRepo.GetCharacteristicsById(ctx, dto.ID); err != nil {
+			return err
+		}
+
+		if err := s.characteristicRepo.UpdateCharacteristics(ctx, &row); err != nil {
+			log.FromContext(ctx).Error("Failed to update characteristic", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &row, nil
 }
 
-func (s *characteristicService) DeleteCharacteristic(id int) error {
-	if err := repository.CharacteristicRepo.DeleteCharacteristicsById(id); err != nil {
-		log.Error("Failed to delete characteristics", zap.Error(err))
+func (s *characteristicService) DeleteCharacteristic(ctx context.Context, id int) error {
+	if err := s.characteristicRepo.DeleteCharacteristicsById(ctx, id); err != nil {
+		log.FromContext(ctx).Error("Failed to delete characteristics", zap.Error(err))
 		return err
 	}
 	return nil
 }
 
-func (s *characteristicService) GetCharForFilters(nodeTypeId int) (*[]model.CharFilterResponse, error) {
+func (s *characteristicService) GetCharForFilters(ctx context.Context, nodeTypeId int, includeRemoved bool) (*[]model.CharFilterResponse, error) {
 	// Получаем данные из репозитория
-	filters, err := repository.CharacteristicRepo.GetCharFilters(nodeTypeId)
+	filters, err := s.characteristicRepo.GetCharFilters(ctx, nodeTypeId, includeRemoved)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"shop/configs/env"
+	"shop/pkg/log"
+	"time"
+)
+
+// defaultAccessTokenTTL — время жизни access-токена, если JWT_ACCESS_TTL не задан.
+const defaultAccessTokenTTL = time.Hour
+
+// Claims — полезная нагрузка access-токена.
+type Claims struct {
+	UserId string `json:"userId"`
+	Role   string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+type jwtService struct{}
+
+type JWTServiceInterface interface {
+	GenerateToken(userId, role string) (string, time.Duration, error)
+	ValidateToken(tokenStr string) (*jwt.Token, error)
+}
+
+func NewJWTService() JWTServiceInterface {
+	return &jwtService{}
+}
+
+// GenerateToken выпускает подписанный HS256 access-токен для пользователя и возвращает его вместе со временем жизни.
+func (s *jwtService) GenerateToken(userId, role string) (string, time.Duration, error) {
+	key, err := secretKey()
+	if err != nil {
+		return "", 0, err
+	}
+
+	ttl := accessTokenTTL()
+	now := time.Now()
+
+	claims := &Claims{
+		UserId: userId,
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userId,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+	if err != nil {
+		log.Error("Failed to sign access token", zap.Error(err))
+		return "", 0, err
+	}
+
+	return signed, ttl, nil
+}
+
+// ValidateToken разбирает токен, проверяет подпись, алгоритм и срок действия.
+func (s *jwtService) ValidateToken(tokenStr string) (*jwt.Token, error) {
+	key, err := secretKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return jwt.ParseWithClaims(
+		tokenStr,
+		&Claims{},
+		func(token *jwt.Token) (interface{}, error) {
+			return key, nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+}
+
+// secretKey читает ключ подписи из переменной окружения JWT_KEY.
+func secretKey() ([]byte, error) {
+	key := env.GetEnv("JWT_KEY", "")
+	if key == "" {
+		return nil, errors.New("JWT_KEY is not set in environment variables")
+	}
+	return []byte(key), nil
+}
+
+// accessTokenTTL читает время жизни access-токена из JWT_ACCESS_TTL (например, "15m" или "1h").
+func accessTokenTTL() time.Duration {
+	raw := env.GetEnv("JWT_ACCESS_TTL", "")
+	if raw == "" {
+		return defaultAccessTokenTTL
+	}
+
+	ttl, err := time.ParseDuration(raw)
+	if err != nil || ttl <= 0 {
+		log.Warn(fmt.Sprintf("Invalid JWT_ACCESS_TTL, using default %s", defaultAccessTokenTTL), zap.String("value", raw))
+		return defaultAccessTokenTTL
+	}
+	return ttl
+}
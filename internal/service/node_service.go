@@ -1,33 +1,50 @@
 package service
 
 import (
+	"context"
+	"fmt"
 	"go.uber.org/zap"
+	"shop/configs/env"
 	"shop/internal/api/dto"
 	"shop/internal/model"
 	"shop/internal/repository"
+	"shop/pkg/app_error"
 	"shop/pkg/log"
 	"shop/pkg/utils"
+	"strconv"
+	"time"
 )
 
-type nodeService struct{}
+// defaultNodesRetentionDays — сколько дней удалённая нода хранится до физического удаления,
+// если NODES_RETENTION_DAYS не задан.
+const defaultNodesRetentionDays = 30
 
-type NodeServiceInterface interface {
-	GetAllNode(pageNumber, pageSize int) (*model.Paginate[model.NodeRow], error)
-	CreateNode(size *dto.CreateNodeRequest) (*model.NodeRow, error)
-	UpdateNode(size *dto.UpdateNodeRequest) (*model.NodeRow, error)
-	DeleteNode(id int) error
+// defaultSearchBackfillBatch — размер пачки пересчёта поисковых векторов по умолчанию.
+const defaultSearchBackfillBatch = 500
+
+type nodeService struct {
+	nodeRepo     repository.NodeRepositoryInterface
+	nodeTypeRepo repository.NodeTypeRepositoryInterface
 }
 
-func NewNodeService() NodeServiceInterface {
-	return &nodeService{}
+type NodeServiceInterface interface {
+	GetAllNode(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[model.NodeRow], error)
+	CreateNode(ctx context.Context, size *dto.CreateNodeRequest) (*model.NodeRow, error)
+	UpdateNode(ctx context.Context, size *dto.UpdateNodeRequest) (*model.NodeRow, error)
+	DeleteNode(ctx context.Context, id int) error
+	RestoreNode(ctx context.Context, id int) (*model.NodeRow, error)
+	PurgeRemovedNodes(ctx context.Context, olderThanDays *int) (*model.PurgeNodesResponse, error)
+	BackfillSearchVectors(ctx context.Context, batchSize int) (int, error)
 }
 
-var NodeService = NewNodeService()
+func NewNodeService(nodeRepo repository.NodeRepositoryInterface, nodeTypeRepo repository.NodeTypeRepositoryInterface) NodeServiceInterface {
+	return &nodeService{nodeRepo: nodeRepo, nodeTypeRepo: nodeTypeRepo}
+}
 
-func (s *nodeService) GetAllNode(pageNumber, pageSize int) (*model.Paginate[model.NodeRow], error) {
-	nodeTypes, totalCount, err := repository.NodeRepo.GetAllNodes(pageNumber, pageSize)
+func (s *nodeService) GetAllNode(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[model.NodeRow], error) {
+	nodeTypes, totalCount, err := s.nodeRepo.GetAllNodes(ctx, pageNumber, pageSize)
 	if err != nil {
-		log.Error("Failed to fetch node", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch node", zap.Error(err))
 		return nil, err
 	}
 
@@ -43,18 +60,18 @@ func (s *nodeService) GetAllNode(pageNumber, pageSize int) (*model.Paginate[mode
 	return result, nil
 }
 
-func (s *nodeService) CreateNode(dto *dto.CreateNodeRequest) (*model.NodeRow, error) {
-	_, err := repository.NodeTypeRepo.GetNodeTypeById(dto.NodeTypeId)
+func (s *nodeService) CreateNode(ctx context.Context, dto *dto.CreateNodeRequest) (*model.NodeRow, error) {
+	_, err := s.nodeTypeRepo.GetNodeTypeById(ctx, dto.NodeTypeId)
 	if err != nil {
-		log.Error("NodeTypeId not found", zap.Error(err))
+		log.FromContext(ctx).Error("NodeTypeId not found", zap.Error(err))
 		return nil, err
 	}
-	createdID, err := repository.NodeRepo.CreateNode(dto)
+	createdID, err := s.nodeRepo.CreateNode(ctx, dto)
 	if err != nil {
-		log.Error("Failed to create node", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to create node", zap.Error(err))
 		return nil, err
 	}
-	nodeType, err := repository.NodeRepo.GetNodeById(createdID)
+	nodeType, err := s.nodeRepo.GetNodeById(ctx, createdID)
 	if err != nil {
 		return nil, err
 	}
@@ -62,36 +79,139 @@ func (s *nodeService) CreateNode(dto *dto.CreateNodeRequest) (*model.NodeRow, er
 	return nodeType, nil
 }
 
-func (s *nodeService) UpdateNode(dto *dto.UpdateNodeRequest) (*model.NodeRow, error) {
-	_, err := repository.NodeRepo.GetNodeById(dto.ID)
+func (s *nodeService) UpdateNode(ctx context.Context, dto *dto.UpdateNodeRequest) (*model.NodeRow, error) {
+	_, err := s.nodeRepo.GetNodeById(ctx, dto.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = repository.NodeTypeRepo.GetNodeTypeById(dto.NodeTypeId)
+	_, err = s.nodeTypeRepo.GetNodeTypeById(ctx, dto.NodeTypeId)
 	if err != nil {
-		log.Error("NodeTypeId not found", zap.Error(err))
+		log.FromContext(ctx).Error("NodeTypeId not found", zap.Error(err))
 		return nil, err
 	}
 
-	if err := repository.NodeRepo.UpdateNodes(dto); err != nil {
-		log.Error("Failed to update node", zap.Error(err))
+	if err := s.nodeRepo.UpdateNodes(ctx, dto); err != nil {
+		log.FromContext(ctx).Error("Failed to update node", zap.Error(err))
 		return nil, err
 	}
 
-	updatedNode, err := repository.NodeRepo.GetNodeById(dto.ID)
+	updatedNode, err := s.nodeRepo.GetNodeById(ctx, dto.ID)
 	if err != nil {
-		log.Error("Failed to update node", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to update node", zap.Error(err))
 		return nil, err
 	}
 
 	return updatedNode, nil
 }
 
-func (s *nodeService) DeleteNode(id int) error {
-	if err := repository.NodeRepo.DeleteNodeById(id); err != nil {
-		log.Error("Failed to delete node", zap.Error(err))
+// DeleteNode помечает ноду удалённой. Если нода уже удалена, возвращается 409 Conflict.
+func (s *nodeService) DeleteNode(ctx context.Context, id int) error {
+	removed, err := s.nodeRepo.DeleteNodeById(ctx, id)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to delete node", zap.Error(err))
 		return err
 	}
-	return nil
+	if removed {
+		return nil
+	}
+
+	// Ничего не обновлено: ноды нет (404) либо она уже удалена
+	if _, err := s.nodeRepo.GetNodeById(ctx, id); err != nil {
+		return err
+	}
+	return app_error.Conflict("Node is already removed")
+}
+
+// RestoreNode снимает с ноды пометку об удалении. Если нода не удалена, возвращается 409 Conflict.
+func (s *nodeService) RestoreNode(ctx context.Context, id int) (*model.NodeRow, error) {
+	node, err := s.nodeRepo.GetNodeById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if node.RemovedAt == nil {
+		return nil, app_error.Conflict("Node is not removed")
+	}
+
+	restored, err := s.nodeRepo.RestoreNodeById(ctx, id)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to restore node", zap.Int("id", id), zap.Error(err))
+		return nil, err
+	}
+	if !restored {
+		return nil, app_error.Conflict("Node is not removed")
+	}
+
+	return s.nodeRepo.GetNodeById(ctx, id)
+}
+
+// PurgeRemovedNodes физически удаляет ноды, удалённые больше olderThanDays дней назад.
+// Если olderThanDays == nil, срок берётся из NODES_RETENTION_DAYS.
+func (s *nodeService) PurgeRemovedNodes(ctx context.Context, olderThanDays *int) (*model.PurgeNodesResponse, error) {
+	days := nodesRetentionDays()
+	if olderThanDays != nil {
+		days = *olderThanDays
+	}
+
+	// removed_at проставляется в UTC (см. DeleteNodeById)
+	removedBefore := time.Now().UTC().AddDate(0, 0, -days)
+
+	ids, err := s.nodeRepo.PurgeRemovedNodes(ctx, removedBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	log.FromContext(ctx).Info("Removed nodes purged", zap.Int("count", len(ids)), zap.Time("removedBefore", removedBefore))
+
+	return &model.PurgeNodesResponse{
+		RemovedBefore: removedBefore.Format(time.RFC3339),
+		PurgedCount:   len(ids),
+		PurgedIds:     ids,
+	}, nil
+}
+
+// BackfillSearchVectors пересчитывает поисковые векторы всех нод пачками по batchSize
+// и возвращает количество обработанных нод. Каждая пачка — отдельный запрос, поэтому
+// блокировки строк не держатся на всё время пересчёта.
+func (s *nodeService) BackfillSearchVectors(ctx context.Context, batchSize int) (int, error) {
+	if batchSize < 1 {
+		batchSize = defaultSearchBackfillBatch
+	}
+
+	total, afterId := 0, 0
+	for {
+		ids, err := s.nodeRepo.RecomputeSearchVectors(ctx, afterId, batchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		total += len(ids)
+		for _, id := range ids {
+			if id > afterId {
+				afterId = id
+			}
+		}
+		log.FromContext(ctx).Info("Search vectors recomputed", zap.Int("batch", len(ids)), zap.Int("total", total), zap.Int("lastId", afterId))
+	}
+
+	return total, nil
+}
+
+// nodesRetentionDays читает срок хранения удалённых нод из NODES_RETENTION_DAYS.
+func nodesRetentionDays() int {
+	raw := env.GetEnv("NODES_RETENTION_DAYS", "")
+	if raw == "" {
+		return defaultNodesRetentionDays
+	}
+
+	days, err := strconv.Atoi(raw)
+	if err != nil || days < 1 {
+		log.Warn(fmt.Sprintf("Invalid NODES_RETENTION_DAYS, using default %d", defaultNodesRetentionDays), zap.String("value", raw))
+		return defaultNodesRetentionDays
+	}
+	return days
 }
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"shop/internal/model"
+	"shop/internal/repository"
+	"shop/pkg/app_error"
+	"testing"
+	"time"
+)
+
+// fakeNodeRepo хранит пометки об удалении нод в памяти.
+type fakeNodeRepo struct {
+	repository.NodeRepositoryInterface
+	removedAt map[int]*string
+}
+
+func (r *fakeNodeRepo) DeleteNodeById(_ context.Context, id int) (bool, error) {
+	removedAt, ok := r.removedAt[id]
+	if !ok || removedAt != nil {
+		return false, nil
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	r.removedAt[id] = &now
+	return true, nil
+}
+
+func (r *fakeNodeRepo) GetNodeById(_ context.Context, id int) (*model.NodeRow, error) {
+	removedAt, ok := r.removedAt[id]
+	if !ok {
+		return nil, app_error.NotFound("Node not found")
+	}
+	return &model.NodeRow{ID: id, RemovedAt: removedAt}, nil
+}
+
+func TestDeleteNode(t *testing.T) {
+	removed := "2026-01-02T03:04:05Z"
+	repo := &fakeNodeRepo{removedAt: map[int]*string{1: nil, 2: &removed}}
+	serv := NewNodeService(repo, nil)
+
+	assert.NoError(t, serv.DeleteNode(context.Background(), 1))
+	assert.NotNil(t, repo.removedAt[1])
+
+	err := serv.DeleteNode(context.Background(), 2)
+	assert.True(t, errors.Is(err, app_error.ErrConflict))
+	assert.Equal(t, &removed, repo.removedAt[2], "removed_at must not move on repeated delete")
+
+	err = serv.DeleteNode(context.Background(), 3)
+	assert.True(t, errors.Is(err, app_error.ErrNotFound))
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"go.uber.org/zap"
 	"shop/internal/api/dto"
 	"shop/internal/model"
@@ -9,25 +10,25 @@ import (
 	"shop/pkg/utils"
 )
 
-type nodeTypeService struct{}
+type nodeTypeService struct {
+	nodeTypeRepo repository.NodeTypeRepositoryInterface
+}
 
 type NodeTypeServiceInterface interface {
-	GetAllNodeType(pageNumber, pageSize int) (*model.Paginate[model.NodeTypeRow], error)
-	CreateNodeType(size *dto.CreateNodeTypeRequest) (*model.NodeTypeRow, error)
-	UpdateNodeType(size *dto.UpdateNodeTypeRequest) (*model.NodeTypeRow, error)
-	DeleteNodeType(id int) error
+	GetAllNodeType(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[model.NodeTypeRow], error)
+	CreateNodeType(ctx context.Context, size *dto.CreateNodeTypeRequest) (*model.NodeTypeRow, error)
+	UpdateNodeType(ctx context.Context, size *dto.UpdateNodeTypeRequest) (*model.NodeTypeRow, error)
+	DeleteNodeType(ctx context.Context, id int) error
 }
 
-func NewNodeTypeService() NodeTypeServiceInterface {
-	return &nodeTypeService{}
+func NewNodeTypeService(nodeTypeRepo repository.NodeTypeRepositoryInterface) NodeTypeServiceInterface {
+	return &nodeTypeService{nodeTypeRepo: nodeTypeRepo}
 }
 
-var NodeTypeService = NewNodeTypeService()
-
-func (s *nodeTypeService) GetAllNodeType(pageNumber, pageSize int) (*model.Paginate[model.NodeTypeRow], error) {
-	nodeTypes, totalCount, err := repository.NodeTypeRepo.GetAllNodeTypes(pageNumber, pageSize)
+func (s *nodeTypeService) GetAllNodeType(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[model.NodeTypeRow], error) {
+	nodeTypes, totalCount, err := s.nodeTypeRepo.GetAllNodeTypes(ctx, pageNumber, pageSize)
 	if err != nil {
-		log.Error("Failed to fetch node_type", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch node_type", zap.Error(err))
 		return nil, err
 	}
 
@@ -43,15 +44,15 @@ func (s *nodeTypeService) GetAllNodeType(pageNumber, pageSize int) (*model.Pagin
 	return result, nil
 }
 
-func (s *nodeTypeService) CreateNodeType(dto *dto.CreateNodeTypeRequest) (*model.NodeTypeRow, error) {
+func (s *nodeTypeService) CreateNodeType(ctx context.Context, dto *dto.CreateNodeTypeRequest) (*model.NodeTypeRow, error) {
 
-	createdID, err := repository.NodeTypeRepo.CreateNodeType(dto)
+	createdID, err := s.nodeTypeRepo.CreateNodeType(ctx, dto)
 	if err != nil {
-		log.Error("Failed to create node_type", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to create node_type", zap.Error(err))
 		return nil, err
 	}
 
-	nodeType, err := repository.NodeTypeRepo.GetNodeTypeById(createdID)
+	nodeType, err := s.nodeTypeRepo.GetNodeTypeById(ctx, createdID)
 	if err != nil {
 		return nil, err
 	}
@@ -59,28 +60,33 @@ func (s *nodeTypeService) CreateNodeType(dto *dto.CreateNodeTypeRequest) (*model
 	return nodeType, nil
 }
 
-func (s *nodeTypeService) UpdateNodeType(dto *dto.UpdateNodeTypeRequest) (*model.NodeTypeRow, error) {
-	_, err := repository.NodeTypeRepo.GetNodeTypeById(dto.ID)
+func (s *nodeTypeService) UpdateNodeType(ctx context.Context, dto *dto.UpdateNodeTypeRequest) (*model.NodeTypeRow, error) {
+	existing, err := s.nodeTypeRepo.GetNodeTypeById(ctx, dto.ID)
 	if err != nil {
 		return nil, err
 	}
 
 	row := model.NodeTypeRow{
-		ID:          dto.ID,
-		Type:        dto.Type,
-		Description: dto.Description,
+		ID:           dto.ID,
+		Type:         dto.Type,
+		Description:  dto.Description,
+		SearchConfig: existing.SearchConfig,
+	}
+	// Если конфигурация поиска не передана, остаётся прежней
+	if dto.SearchConfig != nil {
+		row.SearchConfig = *dto.SearchConfig
 	}
 
-	if err := repository.NodeTypeRepo.UpdateNodeType(&row); err != nil {
-		log.Error("Failed to update node_type", zap.Error(err))
+	if err := s.nodeTypeRepo.UpdateNodeType(ctx, &row); err != nil {
+		log.FromContext(ctx).Error("Failed to update node_type", zap.Error(err))
 		return nil, err
 	}
 	return &row, nil
 }
 
-func (s *nodeTypeService) DeleteNodeType(id int) error {
-	if err := repository.NodeTypeRepo.DeleteNodeTypeById(id); err != nil {
-		log.Error("Failed to delete characteristics", zap.Error(err))
+func (s *nodeTypeService) DeleteNodeType(ctx context.Context, id int) error {
+	if err := s.nodeTypeRepo.DeleteNodeTypeById(ctx, id); err != nil {
+		log.FromContext(ctx).Error("Failed to delete characteristics", zap.Error(err))
 		return err
 	}
 	return nil
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"shop/internal/api/dto"
+	"shop/internal/model"
+	"shop/internal/repository"
+	"shop/pkg/app_error"
+	"shop/pkg/log"
+	"shop/pkg/metrics"
+	"shop/pkg/utils"
+)
+
+type orderService struct {
+	txManager repository.TxManager
+	orderRepo repository.OrderRepositoryInterface
+}
+
+type OrderServiceInterface interface {
+	CreateOrder(ctx context.Context, items *[]dto.OrderDTO, userId *int) (*model.OrderResponse, error)
+	GetOrderById(ctx context.Context, id string) (*model.OrderResponse, error)
+	GetAllOrders(ctx context.Context, pageNumber, pageSize int, filter *model.OrderFilter) (*model.Paginate[model.OrderRow], error)
+	UpdateOrderStatus(ctx context.Context, id string, dto *dto.UpdateOrderStatusRequest, changedBy *string) (*model.OrderResponse, error)
+	GetOrderStatusHistory(ctx context.Context, id string) ([]model.OrderStatusHistoryRow, error)
+}
+
+func NewOrderService(txManager repository.TxManager, orderRepo repository.OrderRepositoryInterface) OrderServiceInterface {
+	return &orderService{txManager: txManager, orderRepo: orderRepo}
+}
+
+// CreateOrder оформляет заказ и возвращает его; резерв остатков и чтение заказа выполняются в одной транзакции.
+func (s *orderService) CreateOrder(ctx context.Context, items *[]dto.OrderDTO, userId *int) (*model.OrderResponse, error) {
+	var order *model.OrderResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		orderId, err := s.orderRepo.CreateOrder(ctx, *items, userId)
+		if err != nil {
+			var stockErr *repository.ErrStockUnavailable
+			if errors.As(err, &stockErr) {
+				details := make([]app_error.FieldError, 0, len(stockErr.Items))
+				for _, item := range stockErr.Items {
+					details = append(details, app_error.FieldError{
+						Field: fmt.Sprintf("items[%d]", item.Index),
+						Error: fmt.Sprintf("node %d: requested %d, available %d", item.NodeId, item.Requested, item.Available),
+					})
+				}
+				return &app_error.Error{Kind: app_error.ErrConflict, Message: "Not enough stock", Details: details}
+			}
+			log.FromContext(ctx).Error("Failed to create order", zap.Error(err))
+			return err
+		}
+
+		order, err = s.GetOrderById(ctx, orderId)
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to fetch order, after creating", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.OrdersCreated.Inc()
+	return order, nil
+}
+
+func (s *orderService) GetOrderById(ctx context.Context, id string) (*model.OrderResponse, error) {
+	order, err := s.orderRepo.GetOrderById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.orderRepo.GetOrderItems(ctx, id)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to fetch order items", zap.String("orderId", id), zap.Error(err))
+		return nil, err
+	}
+
+	return model.MapperOrderResponse(order, items), nil
+}
+
+func (s *orderService) GetAllOrders(ctx context.Context, pageNumber, pageSize int, filter *model.OrderFilter) (*model.Paginate[model.OrderRow], error) {
+	orders, totalCount, err := s.orderRepo.GetAllOrders(ctx, pageNumber, pageSize, filter)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to fetch orders", zap.Error(err))
+		return nil, err
+	}
+
+	result := &model.Paginate[model.OrderRow]{
+		PageNumber:     pageNumber,
+		RowTotalCount:  totalCount,
+		TotalPageCount: utils.CalculateTotalPages(totalCount, pageSize),
+		PageSize:       pageSize,
+		Items:          orders,
+	}
+
+	return result, nil
+}
+
+// UpdateOrderStatus переводит заказ в новый статус. Недопустимый переход отклоняется с 409 Conflict.
+func (s *orderService) UpdateOrderStatus(ctx context.Context, id string, dto *dto.UpdateOrderStatusRequest, changedBy *string) (*model.OrderResponse, error) {
+	var updated *model.OrderResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		order, err := s.orderRepo.GetOrderById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !model.CanTransitionOrder(order.Status, dto.Status) {
+			log.FromContext(ctx).Warn("Illegal order status transition",
+				zap.String("orderId", id),
+				zap.String("from", order.Status),
+				zap.String("to", dto.Status))
+			return app_error.Conflict("Cannot change order status from %s to %s", order.Status, dto.Status)
+		}
+
+		err = s.orderRepo.UpdateOrderStatus(ctx, id, order.Status, dto.Status, changedBy, dto.Comment)
+		if err != nil {
+			if errors.Is(err, repository.ErrOrderStatusConflict) {
+				return app_error.Conflict("Order status was changed by another request")
+			}
+			log.FromContext(ctx).Error("Failed to update order status", zap.Error(err))
+			return err
+		}
+
+		updated, err = s.GetOrderById(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *orderService) GetOrderStatusHistory(ctx context.Context, id string) ([]model.OrderStatusHistoryRow, error) {
+	if _, err := s.orderRepo.GetOrderById(ctx, id); err != nil {
+		return nil, err
+	}
+
+	history, err := s.orderRepo.GetOrderStatusHistory(ctx, id)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to fetch order status history", zap.String("orderId", id), zap.Error(err))
+		return nil, err
+	}
+
+	return history, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"shop/internal/api/dto"
+	"shop/internal/model"
+	"shop/internal/repository"
+	"shop/pkg/app_error"
+	"shop/pkg/http_error"
+	"shop/pkg/log"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	log.InitLogger()
+	os.Exit(m.Run())
+}
+
+// fakeTxManager выполняет fn без транзакции.
+type fakeTxManager struct{}
+
+func (fakeTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// fakeOrderRepo — OrderRepositoryInterface, в котором CreateOrder возвращает заданную ошибку.
+type fakeOrderRepo struct {
+	repository.OrderRepositoryInterface
+	createErr error
+}
+
+func (r *fakeOrderRepo) CreateOrder(context.Context, []dto.OrderDTO, *int) (string, error) {
+	return "", r.createErr
+}
+
+func TestCreateOrderStockConflict(t *testing.T) {
+	size := "M"
+	repo := &fakeOrderRepo{createErr: &repository.ErrStockUnavailable{Items: []model.UnavailableStockItem{
+		{Index: 0, NodeId: 1, Size: &size, Requested: 3, Available: 1},
+		{Index: 2, NodeId: 4, Requested: 1, Available: 0},
+	}}}
+	serv := NewOrderService(fakeTxManager{}, repo)
+
+	order, err := serv.CreateOrder(context.Background(), &[]dto.OrderDTO{}, nil)
+	assert.Nil(t, order)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, app_error.ErrConflict))
+
+	httpErr := http_error.From(err)
+	assert.Equal(t, fiber.StatusConflict, httpErr.StatusCode)
+	assert.Equal(t, "Not enough stock", httpErr.Message)
+	assert.Equal(t, []http_error.ErrorItem{
+		{Field: "items[0]", Error: "node 1: requested 3, available 1"},
+		{Field: "items[2]", Error: "node 4: requested 1, available 0"},
+	}, httpErr.Details)
+}
+
+func TestCreateOrderRepositoryError(t *testing.T) {
+	repoErr := errors.New("connection reset")
+	serv := NewOrderService(fakeTxManager{}, &fakeOrderRepo{createErr: repoErr})
+
+	_, err := serv.CreateOrder(context.Background(), &[]dto.OrderDTO{}, nil)
+	assert.Same(t, repoErr, err)
+}
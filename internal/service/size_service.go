@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"go.uber.org/zap"
 	"shop/internal/api/dto"
 	"shop/internal/model"
@@ -9,25 +10,25 @@ import (
 	"shop/pkg/utils"
 )
 
-type sizeService struct{}
+type sizeService struct {
+	sizeRepo repository.SizeRepositoryInterface
+}
 
 type SizeServiceInterface interface {
-	GetAllSizes(pageNumber, pageSize int) (*model.Paginate[model.SizeRow], error)
-	CreateSize(size *dto.CreateSizeRequest) (*model.SizeRow, error)
-	UpdateSize(size *dto.UpdateSizeRequest) (*model.SizeRow, error)
-	DeleteSize(id int) error
+	GetAllSizes(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[model.SizeRow], error)
+	CreateSize(ctx context.Context, size *dto.CreateSizeRequest) (*model.SizeRow, error)
+	UpdateSize(ctx context.Context, size *dto.UpdateSizeRequest) (*model.SizeRow, error)
+	DeleteSize(ctx context.Context, id int) error
 }
 
-func NewSizeService() SizeServiceInterface {
-	return &sizeService{}
+func NewSizeService(sizeRepo repository.SizeRepositoryInterface) SizeServiceInterface {
+	return &sizeService{sizeRepo: sizeRepo}
 }
 
-var SizeService = NewSizeService()
-
-func (s *sizeService) GetAllSizes(pageNumber, pageSize int) (*model.Paginate[model.SizeRow], error) {
-	sizes, totalCount, err := repository.SizeRepo.GetAllSizes(pageNumber, pageSize)
+func (s *sizeService) GetAllSizes(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[model.SizeRow], error) {
+	sizes, totalCount, err := s.sizeRepo.GetAllSizes(ctx, pageNumber, pageSize)
 	if err != nil {
-		log.Error("Failed to fetch sizes", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to fetch sizes", zap.Error(err))
 		return nil, err
 	}
 
@@ -43,15 +44,15 @@ func (s *sizeService) GetAllSizes(pageNumber, pageSize int) (*model.Paginate[mod
 	return result, nil
 }
 
-func (s *sizeService) CreateSize(size *dto.CreateSizeRequest) (*model.SizeRow, error) {
+func (s *sizeService) CreateSize(ctx context.Context, size *dto.CreateSizeRequest) (*model.SizeRow, error) {
 
-	createdID, err := repository.SizeRepo.CreateSize(size)
+	createdID, err := s.sizeRepo.CreateSize(ctx, size)
 	if err != nil {
-		log.Error("Failed to create size", zap.Error(err))
+		log.FromContext(ctx).Error("Failed to create size", zap.Error(err))
 		return nil, err
 	}
 
-	sizeRow, err := repository.SizeRepo.GetSizeById(createdID)
+	sizeRow, err := s.sizeRepo.GetSizeById(ctx, createdID)
 	if err != nil {
 		return nil, err
 	}
@@ -59,8 +60,8 @@ func (s *sizeService) CreateSize(size *dto.CreateSizeRequest) (*model.SizeRow, e
 	return sizeRow, nil
 }
 
-func (s *sizeService) UpdateSize(dto *dto.UpdateSizeRequest) (*model.SizeRow, error) {
-	_, err := repository.SizeRepo.GetSizeById(dto.ID)
+func (s *sizeService) UpdateSize(ctx context.Context, dto *dto.UpdateSizeRequest) (*model.SizeRow, error) {
+	_, err := s.sizeRepo.GetSizeById(ctx, dto.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -71,16 +72,16 @@ func (s *sizeService) UpdateSize(dto *dto.UpdateSizeRequest) (*model.SizeRow, er
 		Description: dto.Description,
 	}
 
-	if err := repository.SizeRepo.UpdateSize(&row); err != nil {
-		log.Error("Failed to update size", zap.Error(err))
+	if err := s.sizeRepo.UpdateSize(ctx, &row); err != nil {
+		log.FromContext(ctx).Error("Failed to update size", zap.Error(err))
 		return nil, err
 	}
 	return &row, nil
 }
 
-func (s *sizeService) DeleteSize(id int) error {
-	if err := repository.SizeRepo.DeleteSizeById(id); err != nil {
-		log.Error("Failed to delete size", zap.Error(err))
+func (s *sizeService) DeleteSize(ctx context.Context, id int) error {
+	if err := s.sizeRepo.DeleteSizeById(ctx, id); err != nil {
+		log.FromContext(ctx).Error("Failed to delete size", zap.Error(err))
 		return err
 	}
 	return nil
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"shop/internal/api/dto"
+	"shop/internal/model"
+	"shop/internal/repository"
+	"shop/pkg/log"
+	"shop/pkg/utils"
+)
+
+type stockService struct {
+	txManager repository.TxManager
+	stockRepo repository.StockRepositoryInterface
+	nodeRepo  repository.NodeRepositoryInterface
+	sizeRepo  repository.SizeRepositoryInterface
+}
+
+type StockServiceInterface interface {
+	GetAllStock(ctx context.Context, pageNumber, pageSize, nodeId int) (*model.Paginate[model.StockRow], error)
+	CreateStock(ctx context.Context, stock *dto.CreateStockRequest) (*model.StockRow, error)
+	UpdateStock(ctx context.Context, stock *dto.UpdateStockRequest) (*model.StockRow, error)
+	DeleteStock(ctx context.Context, id int) error
+}
+
+func NewStockService(
+	txManager repository.TxManager,
+	stockRepo repository.StockRepositoryInterface,
+	nodeRepo repository.NodeRepositoryInterface,
+	sizeRepo repository.SizeRepositoryInterface,
+) StockServiceInterface {
+	return &stockService{
+		txManager: txManager,
+		stockRepo: stockRepo,
+		nodeRepo:  nodeRepo,
+		sizeRepo:  sizeRepo,
+	}
+}
+
+func (s *stockService) GetAllStock(ctx context.Context, pageNumber, pageSize, nodeId int) (*model.Paginate[model.StockRow], error) {
+	stock, totalCount, err := s.stockRepo.GetAllStock(ctx, pageNumber, pageSize, nodeId)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to fetch stock", zap.Error(err))
+		return nil, err
+	}
+
+	result := &model.Paginate[model.StockRow]{
+		PageNumber:     pageNumber,
+		RowTotalCount:  totalCount,
+		TotalPageCount: utils.CalculateTotalPages(totalCount, pageSize),
+		PageSize:       pageSize,
+		Items:          stock,
+	}
+
+	return result, nil
+}
+
+func (s *stockService) CreateStock(ctx context.Context, dto *dto.CreateStockRequest) (*model.StockRow, error) {
+	var stock *model.StockRow
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.nodeRepo.GetNodeById(ctx, dto.NodeId); err != nil {
+			log.FromContext(ctx).Error("NodeId not found", zap.Error(err))
+			return err
+		}
+
+		if _, err := s.sizeRepo.GetSizeById(ctx, dto.SizeId); err != nil {
+			log.FromContext(ctx).Error("SizeId not found", zap.Error(err))
+			return err
+		}
+
+		createdID, err := s.stockRepo.CreateStock(ctx, dto)
+		if err != nil {
+			log.FromContext(ctx).Error("Failed to create stock", zap.Error(err))
+			return err
+		}
+
+		stock, err = s.stockRepo.GetStockById(ctx, createdID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stock, nil
+}
+
+func (s *stockService) UpdateStock(ctx context.Context, dto *dto.UpdateStockRequest) (*model.StockRow, error) {
+	var stock *model.StockRow
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.stockRepo.GetStockById(ctx, dto.ID); err != nil {
+			return err
+		}
+
+		if err := s.stockRepo.UpdateStock(ctx, dto); err != nil {
+			log.FromContext(ctx).Error("Failed to update stock", zap.Error(err))
+			return err
+		}
+
+		var err error
+		stock, err = s.stockRepo.GetStockById(ctx, dto.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stock, nil
+}
+
+func (s *stockService) DeleteStock(ctx context.Context, id int) error {
+	// Несуществующий id репозиторий возвращает как app_error.NotFound (404)
+	return s.stockRepo.DeleteStockById(ctx, id)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"shop/configs/env"
+	"shop/internal/model"
+	"shop/internal/repository"
+	"shop/pkg/log"
+	"shop/pkg/metrics"
+	"sync"
+	"time"
+)
+
+// defaultSuggestTimeout — время на сбор подсказок, если SUGGEST_TIMEOUT не задан.
+const defaultSuggestTimeout = 150 * time.Millisecond
+
+// pgQueryCanceled — код ошибки PostgreSQL для запроса, прерванного по отмене или statement_timeout.
+const pgQueryCanceled = "57014"
+
+type suggestService struct {
+	suggestRepo repository.SuggestRepositoryInterface
+}
+
+type SuggestServiceInterface interface {
+	Suggest(ctx context.Context, query string, limit int) (*model.SuggestResponse, error)
+}
+
+func NewSuggestService(suggestRepo repository.SuggestRepositoryInterface) SuggestServiceInterface {
+	return &suggestService{suggestRepo: suggestRepo}
+}
+
+// Suggest собирает подсказки по названиям нод, типам нод и значениям характеристик, не больше limit каждого вида.
+// Три запроса выполняются параллельно с общим ограничением времени; запросы, не успевшие за него,
+// прерываются, их списки остаются пустыми, а в ответе выставляется Partial.
+// Отмена самого ctx (запроса клиента) возвращается как ошибка.
+func (s *suggestService) Suggest(ctx context.Context, query string, limit int) (*model.SuggestResponse, error) {
+	suggestCtx, cancel := context.WithTimeout(ctx, suggestTimeout())
+	defer cancel()
+
+	result := &model.SuggestResponse{
+		Titles:    make([]model.TitleSuggestion, 0),
+		NodeTypes: make([]model.NodeTypeSuggestion, 0),
+		Values:    make([]model.CharValueSuggestion, 0),
+	}
+
+	var (
+		wg                            sync.WaitGroup
+		titlesErr, typesErr, valueErr error
+		titles                        []model.TitleSuggestion
+		nodeTypes                     []model.NodeTypeSuggestion
+		values                        []model.CharValueSuggestion
+	)
+
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		titles, titlesErr = s.suggestRepo.SuggestTitles(suggestCtx, query, limit)
+	}()
+	go func() {
+		defer wg.Done()
+		nodeTypes, typesErr = s.suggestRepo.SuggestNodeTypes(suggestCtx, query, limit)
+	}()
+	go func() {
+		defer wg.Done()
+		values, valueErr = s.suggestRepo.SuggestCharValues(suggestCtx, query, limit)
+	}()
+	wg.Wait()
+
+	for _, part := range []struct {
+		name string
+		err  error
+	}{
+		{"titles", titlesErr},
+		{"nodeTypes", typesErr},
+		{"values", valueErr},
+	} {
+		if part.err == nil {
+			continue
+		}
+		if !isQueryCanceled(part.err) || ctx.Err() != nil {
+			log.FromContext(ctx).Error("Failed to fetch suggestions", zap.String("part", part.name), zap.Error(part.err))
+			return nil, part.err
+		}
+		log.FromContext(ctx).Warn("Suggestions timed out", zap.String("part", part.name), zap.String("query", query))
+		result.Partial = true
+	}
+
+	if titles != nil {
+		result.Titles = titles
+	}
+	if nodeTypes != nil {
+		result.NodeTypes = nodeTypes
+	}
+	if values != nil {
+		result.Values = values
+	}
+	if !result.Partial && len(result.Titles) == 0 && len(result.NodeTypes) == 0 && len(result.Values) == 0 {
+		metrics.SearchZeroResults.WithLabelValues("suggest").Inc()
+	}
+
+	return result, nil
+}
+
+// isQueryCanceled сообщает, что запрос был прерван по истечении контекста.
+func isQueryCanceled(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pgQueryCanceled
+}
+
+// suggestTimeout читает время на сбор подсказок из SUGGEST_TIMEOUT (например, "150ms").
+func suggestTimeout() time.Duration {
+	raw := env.GetEnv("SUGGEST_TIMEOUT", "")
+	if raw == "" {
+		return defaultSuggestTimeout
+	}
+
+	timeout, err := time.ParseDuration(raw)
+	if err != nil || timeout <= 0 {
+		log.Warn(fmt.Sprintf("Invalid SUGGEST_TIMEOUT, using default %s", defaultSuggestTimeout), zap.String("value", raw))
+		return defaultSuggestTimeout
+	}
+	return timeout
+}
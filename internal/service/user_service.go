@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"shop/configs/env"
+	"shop/internal/api/dto"
+	"shop/internal/model"
+	"shop/internal/repository"
+	"shop/pkg/app_error"
+	"shop/pkg/log"
+	"shop/pkg/utils"
+	"strings"
+)
+
+// passwordHashCost — стоимость bcrypt для паролей пользователей.
+const passwordHashCost = bcrypt.DefaultCost
+
+// dummyPasswordHash — bcrypt-хеш (стоимость passwordHashCost) произвольного пароля. С ним сравнивается
+// пароль при входе с неизвестным email, чтобы такой ответ занимал столько же времени, сколько неверный пароль.
+const dummyPasswordHash = "$2a$10$sxVzxtbaaoWJB6q96ol3ieD0YMMVx1.03VMy6TJSLKrtT01nHmNhS"
+
+type userService struct {
+	userRepo repository.UserRepositoryInterface
+}
+
+type UserServiceInterface interface {
+	Register(ctx context.Context, dto *dto.CreateUserRequest) (*model.UserRow, error)
+	CreateNewAdmin(ctx context.Context, dto *dto.CreateUserRequest) (*model.UserRow, error)
+	GetUserById(ctx context.Context, id int) (*model.UserRow, error)
+	Authenticate(ctx context.Context, email, password string) (*model.UserRow, error)
+	UpdateUserRole(ctx context.Context, id int, role string) (*model.UserRow, error)
+	BootstrapSuperAdmin(ctx context.Context) error
+}
+
+func NewUserService(userRepo repository.UserRepositoryInterface) UserServiceInterface {
+	return &userService{userRepo: userRepo}
+}
+
+// Register регистрирует нового покупателя.
+func (s *userService) Register(ctx context.Context, dto *dto.CreateUserRequest) (*model.UserRow, error) {
+	return s.createUser(ctx, dto.Email, dto.Phone, dto.Password, model.RoleCustomer)
+}
+
+// CreateNewAdmin создаёт пользователя с ролью администратора.
+func (s *userService) CreateNewAdmin(ctx context.Context, dto *dto.CreateUserRequest) (*model.UserRow, error) {
+	return s.createUser(ctx, dto.Email, dto.Phone, dto.Password, model.RoleAdmin)
+}
+
+func (s *userService) GetUserById(ctx context.Context, id int) (*model.UserRow, error) {
+	return s.userRepo.GetUserById(ctx, id)
+}
+
+// Authenticate проверяет email и пароль. При любой ошибке проверки возвращается одинаковый 401,
+// чтобы по ответу нельзя было понять, существует ли пользователь.
+func (s *userService) Authenticate(ctx context.Context, email, password string) (*model.UserRow, error) {
+	invalidCredentials := app_error.Unauthorized("Invalid email or password")
+
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if errors.Is(err, app_error.ErrNotFound) {
+		// Сравниваем пароль с фиктивным хешем, чтобы время ответа не выдавало, существует ли пользователь
+		_, _ = utils.CompareHashes(password, dummyPasswordHash)
+		log.FromContext(ctx).Warn("Failed login attempt", zap.String("email", email))
+		return nil, invalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := utils.CompareHashes(password, user.PasswordHash)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to compare password hash", zap.Int("userId", user.ID), zap.Error(err))
+		return nil, err
+	}
+	if !ok {
+		log.FromContext(ctx).Warn("Failed login attempt", zap.String("email", email))
+		return nil, invalidCredentials
+	}
+
+	return user, nil
+}
+
+// UpdateUserRole назначает пользователю роль. Новая роль попадает в токен при следующем входе.
+func (s *userService) UpdateUserRole(ctx context.Context, id int, role string) (*model.UserRow, error) {
+	if !model.IsValidRole(role) {
+		return nil, app_error.Validation("Unknown role")
+	}
+
+	if err := s.userRepo.UpdateUserRole(ctx, id, role); err != nil {
+		return nil, err
+	}
+
+	return s.userRepo.GetUserById(ctx, id)
+}
+
+// BootstrapSuperAdmin создаёт первого администратора из SUPER_ADMIN_LOGIN / SUPER_ADMIN_PASSWORD,
+// если в базе ещё нет ни одного администратора. Если переменные не заданы, ничего не делает.
+func (s *userService) BootstrapSuperAdmin(ctx context.Context) error {
+	login := env.GetEnv("SUPER_ADMIN_LOGIN", "")
+	password := env.GetEnv("SUPER_ADMIN_PASSWORD", "")
+	if login == "" || password == "" {
+		log.FromContext(ctx).Warn("SUPER_ADMIN_LOGIN or SUPER_ADMIN_PASSWORD is not set, skipping admin bootstrap")
+		return nil
+	}
+
+	exists, err := s.userRepo.ExistsUserWithRole(ctx, model.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	admin, err := s.createUser(ctx, login, "", password, model.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Info("Super admin created", zap.Int("userId", admin.ID), zap.String("email", admin.Email))
+	return nil
+}
+
+func (s *userService) createUser(ctx context.Context, email, phone, password, role string) (*model.UserRow, error) {
+	email = strings.TrimSpace(email)
+
+	hash, err := utils.HashData(password, passwordHashCost)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to hash password", zap.Error(err))
+		return nil, err
+	}
+
+	id, err := s.userRepo.CreateUser(ctx, &model.UserRow{
+		Email:        email,
+		Phone:        phone,
+		PasswordHash: hash,
+		Role:         role,
+	})
+	if err != nil {
+		// Занятый email (уникальный индекс по lower(email)) репозиторий возвращает как app_error.Conflict (409)
+		if !errors.Is(err, app_error.ErrConflict) {
+			log.FromContext(ctx).Error("Failed to create user", zap.String("email", email), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserById(ctx, id)
+	if err != nil {
+		log.FromContext(ctx).Error("Failed to fetch user, after creating", zap.Error(err))
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,153 @@
+package app_error
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"strings"
+)
+
+// Виды доменных ошибок. Проверяются через errors.Is, например errors.Is(err, app_error.ErrNotFound).
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrForbidden    = errors.New("forbidden")
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// Коды ошибок PostgreSQL, которые переводятся в доменные ошибки.
+const (
+	pgNotNullViolation    = "23502"
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+	pgCheckViolation      = "23514"
+)
+
+// Error — доменная ошибка: вид (один из Err*), сообщение для клиента, пояснения по полям (необязательно)
+// и исходная причина (может быть nil). Причина клиенту не передаётся.
+type Error struct {
+	Kind    error
+	Message string
+	Details []FieldError
+	Err     error
+}
+
+// FieldError — пояснение к ошибке, относящееся к конкретному полю запроса.
+type FieldError struct {
+	Field string
+	Error string
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Is сопоставляет ошибку с её видом.
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NotFound — запрошенная сущность не существует.
+func NotFound(format string, args ...interface{}) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// Conflict — операция противоречит текущему состоянию данных.
+func Conflict(format string, args ...interface{}) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// Validation — данные запроса корректны по форме, но не могут быть приняты.
+func Validation(format string, args ...interface{}) error {
+	return &Error{Kind: ErrValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// Forbidden — операция запрещена текущему пользователю.
+func Forbidden(format string, args ...interface{}) error {
+	return &Error{Kind: ErrForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+// Unauthorized — учётные данные или токен неверны либо устарели.
+func Unauthorized(format string, args ...interface{}) error {
+	return &Error{Kind: ErrUnauthorized, Message: fmt.Sprintf(format, args...)}
+}
+
+// FromPostgres переводит нарушения ограничений PostgreSQL в доменные ошибки:
+// уникальность — Conflict; внешний ключ — Validation, если запись ссылается на несуществующую строку,
+// и Conflict, если удаляется строка, на которую ещё ссылаются; NOT NULL и CHECK — Validation.
+// Сообщение для клиента выбирается по имени ограничения (см. constraintMessages): pqErr.Detail содержит
+// значения ключей, email и имена таблиц, поэтому остаётся только в причине ошибки для логов.
+// Прочие ошибки возвращаются без изменений.
+func FromPostgres(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case pgUniqueViolation:
+		return &Error{Kind: ErrConflict, Message: constraintMessage(constraintMessages, pqErr, "Record already exists"), Err: err}
+	case pgForeignKeyViolation:
+		// "update or delete on table ..." — удаляется (или меняется) строка, на которую ссылаются другие
+		if strings.HasPrefix(pqErr.Message, "update or delete") {
+			return &Error{Kind: ErrConflict, Message: constraintMessage(referencedMessages, pqErr, "Record is still referenced"), Err: err}
+		}
+		return &Error{Kind: ErrValidation, Message: constraintMessage(constraintMessages, pqErr, "Referenced record does not exist"), Err: err}
+	case pgNotNullViolation:
+		return &Error{Kind: ErrValidation, Message: "Required field is missing", Err: err}
+	case pgCheckViolation:
+		return &Error{Kind: ErrValidation, Message: constraintMessage(constraintMessages, pqErr, "Value is out of the allowed range"), Err: err}
+	}
+	return err
+}
+
+// constraintMessages — сообщения для клиента по именам ограничений схемы (db/migrations):
+// нарушение уникальности, ссылка на несуществующую строку и CHECK.
+var constraintMessages = map[string]string{
+	"users_email_key":       "User with this email already exists",
+	"users_email_lower_key": "User with this email already exists",
+	"users_role_check":      "Unknown role",
+	"size_title_key":        "Size with this title already exists",
+
+	"char_default_value_characteristic_id_value_key":            "Default value already exists",
+	"characteristic_values_node_id_characteristic_id_value_key": "Characteristic value already exists",
+	"stock_node_id_size_id_key":                                 "Stock for this size already exists",
+
+	"nodes_node_type_id_fkey":                      "Node type not found",
+	"char_default_value_characteristic_id_fkey":    "Characteristic not found",
+	"characteristic_values_characteristic_id_fkey": "Characteristic not found",
+	"characteristic_values_node_id_fkey":           "Node not found",
+	"order_items_node_id_fkey":                     "Node not found",
+	"order_items_size_id_fkey":                     "Size not found",
+	"stock_node_id_fkey":                           "Node not found",
+	"stock_size_id_fkey":                           "Size not found",
+	"orders_user_id_fkey":                          "User not found",
+	"refresh_tokens_user_id_fkey":                  "User not found",
+
+	"order_items_amount_check": "Amount must be positive",
+	"stock_quantity_check":     "Quantity must not be negative",
+	"stock_reserved_check":     "Reserved quantity must not be negative",
+	"stock_check":              "Reserved quantity must not exceed quantity",
+}
+
+// referencedMessages — сообщения для клиента, когда удаляется строка, на которую ещё ссылаются.
+var referencedMessages = map[string]string{
+	"order_items_node_id_fkey": "Node is used in orders",
+	"order_items_size_id_fkey": "Size is used in orders",
+}
+
+// constraintMessage возвращает сообщение для нарушенного ограничения или fallback, если ограничение неизвестно.
+func constraintMessage(messages map[string]string, pqErr *pq.Error, fallback string) string {
+	if message, ok := messages[pqErr.Constraint]; ok {
+		return message
+	}
+	return fallback
+}
@@ -0,0 +1,137 @@
+package app_error
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestFromPostgres(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     *pq.Error
+		kind    error
+		message string
+	}{
+		{
+			name:    "unique email",
+			err:     &pq.Error{Code: pgUniqueViolation, Constraint: "users_email_key", Detail: "Key (email)=(john@example.com) already exists."},
+			kind:    ErrConflict,
+			message: "User with this email already exists",
+		},
+		{
+			name:    "case-insensitive unique email",
+			err:     &pq.Error{Code: pgUniqueViolation, Constraint: "users_email_lower_key", Detail: "Key (lower(email))=(john@example.com) already exists."},
+			kind:    ErrConflict,
+			message: "User with this email already exists",
+		},
+		{
+			name:    "unknown unique constraint",
+			err:     &pq.Error{Code: pgUniqueViolation, Constraint: "secret_table_col_key", Detail: "Key (col)=(42) already exists."},
+			kind:    ErrConflict,
+			message: "Record already exists",
+		},
+		{
+			name:    "reference to missing row",
+			err:     &pq.Error{Code: pgForeignKeyViolation, Constraint: "stock_size_id_fkey", Message: `insert or update on table "stock" violates foreign key constraint "stock_size_id_fkey"`, Detail: `Key (size_id)=(9) is not present in table "size".`},
+			kind:    ErrValidation,
+			message: "Size not found",
+		},
+		{
+			name:    "unknown reference",
+			err:     &pq.Error{Code: pgForeignKeyViolation, Constraint: "other_fkey", Message: "insert or update on table", Detail: `Key (x)=(1) is not present in table "y".`},
+			kind:    ErrValidation,
+			message: "Referenced record does not exist",
+		},
+		{
+			name:    "delete of referenced row",
+			err:     &pq.Error{Code: pgForeignKeyViolation, Constraint: "order_items_node_id_fkey", Message: `update or delete on table "nodes" violates foreign key constraint "order_items_node_id_fkey"`, Detail: `Key (id)=(5) is still referenced from table "order_items".`},
+			kind:    ErrConflict,
+			message: "Node is used in orders",
+		},
+		{
+			name:    "delete of unknown referenced row",
+			err:     &pq.Error{Code: pgForeignKeyViolation, Constraint: "other_fkey", Message: "update or delete on table", Detail: `Key (id)=(5) is still referenced from table "t".`},
+			kind:    ErrConflict,
+			message: "Record is still referenced",
+		},
+		{
+			name:    "not null",
+			err:     &pq.Error{Code: pgNotNullViolation, Column: "password_hash", Table: "users"},
+			kind:    ErrValidation,
+			message: "Required field is missing",
+		},
+		{
+			name:    "check",
+			err:     &pq.Error{Code: pgCheckViolation, Constraint: "stock_check", Detail: "Failing row contains (1, 2, 3, 10, 20)."},
+			kind:    ErrValidation,
+			message: "Reserved quantity must not exceed quantity",
+		},
+		{
+			name:    "unknown check",
+			err:     &pq.Error{Code: pgCheckViolation, Constraint: "other_check", Detail: "Failing row contains (1)."},
+			kind:    ErrValidation,
+			message: "Value is out of the allowed range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("repo: %w", tt.err)
+			result := FromPostgres(wrapped)
+
+			var appErr *Error
+			require.True(t, errors.As(result, &appErr))
+			assert.True(t, errors.Is(result, tt.kind))
+			assert.Equal(t, tt.message, appErr.Message)
+			if tt.err.Detail != "" {
+				assert.NotContains(t, appErr.Message, tt.err.Detail)
+			}
+
+			// Исходная ошибка с Detail остаётся доступной для логов
+			var pqErr *pq.Error
+			require.True(t, errors.As(result, &pqErr))
+			assert.Same(t, tt.err, pqErr)
+		})
+	}
+}
+
+func TestFromPostgresKeepsOtherErrors(t *testing.T) {
+	plain := errors.New("boom")
+	assert.Same(t, plain, FromPostgres(plain))
+
+	other := &pq.Error{Code: "40001"}
+	assert.Same(t, other, FromPostgres(other))
+
+	assert.NoError(t, FromPostgres(nil))
+}
+
+func TestErrorKinds(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind error
+	}{
+		{NotFound("Card %d not found", 1), ErrNotFound},
+		{Conflict("conflict"), ErrConflict},
+		{Validation("invalid"), ErrValidation},
+		{Forbidden("forbidden"), ErrForbidden},
+		{Unauthorized("unauthorized"), ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind.Error(), func(t *testing.T) {
+			assert.True(t, errors.Is(tt.err, tt.kind))
+			assert.True(t, errors.Is(fmt.Errorf("wrapped: %w", tt.err), tt.kind))
+			for _, other := range []error{ErrNotFound, ErrConflict, ErrValidation, ErrForbidden, ErrUnauthorized} {
+				if other != tt.kind {
+					assert.False(t, errors.Is(tt.err, other))
+				}
+			}
+		})
+	}
+
+	assert.Equal(t, "Card 1 not found", NotFound("Card %d not found", 1).Error())
+}
@@ -1,7 +1,10 @@
 package http_error
 
 import (
+	"context"
+	"errors"
 	"github.com/gofiber/fiber/v2"
+	"shop/pkg/app_error"
 	"strings"
 )
 
@@ -45,6 +48,12 @@ func NewHTTPError(statusCode int, message string, details []ErrorItem) *HTTPErro
 	}
 }
 
+// Error implements the error interface, so services can return an HTTPError
+// and handlers can extract it with errors.As to answer with the intended status code.
+func (e *HTTPError) Error() string {
+	return e.Message
+}
+
 // Send sends the HTTPError as a JSON response to the client.
 //
 // Parameters:
@@ -58,3 +67,64 @@ func (e *HTTPError) Send(ctx *fiber.Ctx) error {
 		"details": e.Details,
 	})
 }
+
+// From converts any error returned by a handler into an HTTPError:
+//   - an HTTPError is returned as is;
+//   - a *fiber.Error keeps its code and message;
+//   - domain errors (see app_error) and Postgres constraint violations map to
+//     404 (NotFound), 409 (Conflict), 422 (Validation), 403 (Forbidden) and 401 (Unauthorized),
+//     their field details become ErrorItem details;
+//   - an exceeded context deadline maps to 504 like FromContext;
+//   - anything else becomes 500 Internal Server Error without leaking the cause.
+func From(err error) *HTTPError {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return NewHTTPError(fiberErr.Code, fiberErr.Message, nil)
+	}
+
+	var appErr *app_error.Error
+	if errors.As(app_error.FromPostgres(err), &appErr) {
+		var details []ErrorItem
+		for _, detail := range appErr.Details {
+			details = append(details, ErrorItem{Field: detail.Field, Error: detail.Error})
+		}
+		return NewHTTPError(kindStatus(appErr.Kind), appErr.Message, details)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return NewHTTPError(fiber.StatusGatewayTimeout, "Request timed out", nil)
+	}
+
+	return NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil)
+}
+
+// kindStatus returns the HTTP status code for a domain error kind.
+func kindStatus(kind error) int {
+	switch kind {
+	case app_error.ErrNotFound:
+		return fiber.StatusNotFound
+	case app_error.ErrConflict:
+		return fiber.StatusConflict
+	case app_error.ErrValidation:
+		return fiber.StatusUnprocessableEntity
+	case app_error.ErrForbidden:
+		return fiber.StatusForbidden
+	case app_error.ErrUnauthorized:
+		return fiber.StatusUnauthorized
+	}
+	return fiber.StatusInternalServerError
+}
+
+// FromContext returns 504 Gateway Timeout when the deadline of the request context has passed
+// and nil otherwise.
+func FromContext(ctx context.Context) *HTTPError {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return NewHTTPError(fiber.StatusGatewayTimeout, "Request timed out", nil)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package http_error
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"shop/pkg/app_error"
+	"testing"
+)
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		message string
+		details []ErrorItem
+	}{
+		{
+			name:    "http error is kept",
+			err:     NewHTTPError(fiber.StatusBadRequest, "Invalid filter", []ErrorItem{{Field: "Sort", Error: "bad"}}),
+			status:  fiber.StatusBadRequest,
+			message: "Invalid filter",
+			details: []ErrorItem{{Field: "sort", Error: "bad"}},
+		},
+		{
+			name:    "fiber error",
+			err:     fiber.NewError(fiber.StatusMethodNotAllowed, "Method Not Allowed"),
+			status:  fiber.StatusMethodNotAllowed,
+			message: "Method Not Allowed",
+			details: []ErrorItem{},
+		},
+		{
+			name:    "not found",
+			err:     fmt.Errorf("service: %w", app_error.NotFound("Card not found")),
+			status:  fiber.StatusNotFound,
+			message: "Card not found",
+			details: []ErrorItem{},
+		},
+		{
+			name:    "conflict",
+			err:     app_error.Conflict("Node is not removed"),
+			status:  fiber.StatusConflict,
+			message: "Node is not removed",
+			details: []ErrorItem{},
+		},
+		{
+			name:    "validation",
+			err:     app_error.Validation("Unknown role"),
+			status:  fiber.StatusUnprocessableEntity,
+			message: "Unknown role",
+			details: []ErrorItem{},
+		},
+		{
+			name:    "forbidden",
+			err:     app_error.Forbidden("Access denied"),
+			status:  fiber.StatusForbidden,
+			message: "Access denied",
+			details: []ErrorItem{},
+		},
+		{
+			name:    "unauthorized",
+			err:     app_error.Unauthorized("Invalid email or password"),
+			status:  fiber.StatusUnauthorized,
+			message: "Invalid email or password",
+			details: []ErrorItem{},
+		},
+		{
+			name: "domain error with details",
+			err: &app_error.Error{
+				Kind:    app_error.ErrConflict,
+				Message: "Not enough stock",
+				Details: []app_error.FieldError{{Field: "items[0]", Error: "node 1: requested 2, available 1"}},
+			},
+			status:  fiber.StatusConflict,
+			message: "Not enough stock",
+			details: []ErrorItem{{Field: "items[0]", Error: "node 1: requested 2, available 1"}},
+		},
+		{
+			name:    "postgres unique violation",
+			err:     fmt.Errorf("repo: %w", &pq.Error{Code: "23505", Constraint: "users_email_key", Detail: "Key (email)=(a@b.c) already exists."}),
+			status:  fiber.StatusConflict,
+			message: "User with this email already exists",
+			details: []ErrorItem{},
+		},
+		{
+			name:    "deadline exceeded",
+			err:     fmt.Errorf("query: %w", context.DeadlineExceeded),
+			status:  fiber.StatusGatewayTimeout,
+			message: "Request timed out",
+			details: []ErrorItem{},
+		},
+		{
+			name:    "cancelled context is unexpected",
+			err:     fmt.Errorf("query: %w", context.Canceled),
+			status:  fiber.StatusInternalServerError,
+			message: "Internal Server Error",
+			details: []ErrorItem{},
+		},
+		{
+			name:    "unknown error is hidden",
+			err:     errors.New("pq: password authentication failed for user shop"),
+			status:  fiber.StatusInternalServerError,
+			message: "Internal Server Error",
+			details: []ErrorItem{},
+		},
+		{
+			name:    "unknown postgres error is hidden",
+			err:     &pq.Error{Code: "40001", Message: "could not serialize access"},
+			status:  fiber.StatusInternalServerError,
+			message: "Internal Server Error",
+			details: []ErrorItem{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpErr := From(tt.err)
+
+			assert.Equal(t, tt.status, httpErr.StatusCode)
+			assert.Equal(t, tt.message, httpErr.Message)
+			assert.Equal(t, tt.details, httpErr.Details)
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Nil(t, FromContext(context.Background()))
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Nil(t, FromContext(cancelled))
+
+	expired, cancelExpired := context.WithTimeout(context.Background(), 0)
+	defer cancelExpired()
+	assert.Equal(t, fiber.StatusGatewayTimeout, FromContext(expired).StatusCode)
+}
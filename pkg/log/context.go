@@ -0,0 +1,31 @@
+package log
+
+import (
+	"context"
+	"go.uber.org/zap"
+)
+
+// ctxKey is the context key for the request-scoped logger.
+type ctxKey struct{}
+
+// WithLogger returns a copy of ctx carrying l; FromContext(ctx) will return it.
+func WithLogger(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// With returns a copy of ctx whose logger additionally carries fields
+// (e.g. the user id once the request is authenticated).
+func With(ctx context.Context, fields ...zap.Field) context.Context {
+	return WithLogger(ctx, FromContext(ctx).With(fields...))
+}
+
+// FromContext returns the logger attached to ctx by the request logger middleware,
+// so entries are correlated by request_id, method, path and user_id.
+// Outside of a request it falls back to the global logger.
+func FromContext(ctx context.Context) *zap.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*zap.Logger); ok {
+		return l
+	}
+	ensureLoggerInitialized()
+	return base
+}
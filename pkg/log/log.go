@@ -1,33 +1,92 @@
 package log
 
 import (
+	"fmt"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"os"
+	"strings"
 )
 
-var logger *zap.Logger
+var (
+	// base is used directly by callers (see FromContext), logger — by the package-level helpers below
+	base   *zap.Logger
+	logger *zap.Logger
+	// level is shared by all loggers, so SetLevel takes effect immediately
+	level zap.AtomicLevel
+)
 
-// InitLogger initializes the global logger
+// InitLogger initializes the global logger from the environment:
+//   - LOG_FORMAT: "console" (default, colored levels) or "json" for log shippers;
+//   - LOG_LEVEL: debug, info, warn or error (default debug for console, info for json);
+//     it can be changed at runtime with SetLevel;
+//   - LOG_SAMPLING: "true" keeps the first 100 identical entries per second and then every 100th;
+//   - LOG_OUTPUT: comma-separated output paths (default "stderr"), e.g. "stdout,/var/log/shop.log".
+//
+// Passwords, tokens, emails and phone numbers are masked in fields (see redact.go).
 func InitLogger() {
 	if logger != nil {
 		panic("Logger is already initialized")
 	}
 
-	config := zap.NewDevelopmentConfig()
-	config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder // Colored levels
-	config.EncoderConfig.TimeKey = "timestamp"                          // Time key
-	//config.EncoderConfig.CallerKey = "caller"                           // File and line key
-	//config.EncoderConfig.MessageKey = "message"                         // Message key
-	//config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder        // Time format
+	var config zap.Config
+	var warnings []string
+	switch format := os.Getenv("LOG_FORMAT"); format {
+	case "json":
+		config = zap.NewProductionConfig()
+		config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	default:
+		if format != "" && format != "console" {
+			warnings = append(warnings, "Invalid LOG_FORMAT, using console")
+		}
+		config = zap.NewDevelopmentConfig()
+		config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder // Colored levels
+	}
+	config.EncoderConfig.TimeKey = "timestamp" // Time key
 	config.DisableStacktrace = true
 
-	baseLogger, err := config.Build()
+	if raw := os.Getenv("LOG_LEVEL"); raw != "" {
+		if parsed, err := zapcore.ParseLevel(raw); err == nil {
+			config.Level.SetLevel(parsed)
+		} else {
+			warnings = append(warnings, fmt.Sprintf("Invalid LOG_LEVEL, using %s", config.Level.Level()))
+		}
+	}
+
+	config.Sampling = nil
+	if os.Getenv("LOG_SAMPLING") == "true" {
+		config.Sampling = &zap.SamplingConfig{Initial: 100, Thereafter: 100}
+	}
+
+	if raw := os.Getenv("LOG_OUTPUT"); raw != "" {
+		config.OutputPaths = strings.Split(raw, ",")
+	}
+
+	baseLogger, err := config.Build(zap.WrapCore(newRedactCore))
 	if err != nil {
 		panic(err)
 	}
 
 	// Skip 1 call frame for correct log source display
+	level = config.Level
+	base = baseLogger
 	logger = baseLogger.WithOptions(zap.AddCallerSkip(1))
+
+	for _, warning := range warnings {
+		base.Warn(warning)
+	}
+}
+
+// Level returns the current minimum level of the global logger.
+func Level() zapcore.Level {
+	ensureLoggerInitialized()
+	return level.Level()
+}
+
+// SetLevel changes the minimum level of the global logger and every logger derived from it.
+func SetLevel(l zapcore.Level) {
+	ensureLoggerInitialized()
+	level.SetLevel(l)
 }
 
 // GetLogger returns the current logger instance
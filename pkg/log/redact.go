@@ -0,0 +1,112 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"strings"
+)
+
+// redactedValue replaces the value of a secret field.
+const redactedValue = "[REDACTED]"
+
+// secretKeys are substrings of field keys whose values are never written to the log.
+var secretKeys = []string{"password", "secret", "token", "authorization"}
+
+// redactCore masks sensitive fields before they reach the encoder:
+// secrets (passwords, tokens) are replaced completely,
+// emails and phone numbers keep only a few characters to stay recognizable.
+type redactCore struct {
+	zapcore.Core
+}
+
+func newRedactCore(core zapcore.Core) zapcore.Core {
+	return redactCore{Core: core}
+}
+
+func (c redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return redactCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, redactFields(fields))
+}
+
+// redactFields returns fields with sensitive values masked. The slice is copied only if something changed.
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	var result []zapcore.Field
+	for i, field := range fields {
+		masked, ok := redactField(field)
+		if !ok {
+			continue
+		}
+		if result == nil {
+			result = append([]zapcore.Field(nil), fields...)
+		}
+		result[i] = masked
+	}
+	if result == nil {
+		return fields
+	}
+	return result
+}
+
+// redactField masks a single field; ok is false if the field is not sensitive.
+func redactField(field zapcore.Field) (masked zapcore.Field, ok bool) {
+	// Identifiers (id, tokenId, token_id) are not secret and are needed to investigate incidents.
+	// The check is case-sensitive so that keys merely ending in "id" ("paid", "guid") are not exempt.
+	if isIdentifierKey(field.Key) {
+		return field, false
+	}
+
+	key := strings.ToLower(field.Key)
+
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return zap.String(field.Key, redactedValue), true
+		}
+	}
+
+	var mask func(string) string
+	switch {
+	case strings.Contains(key, "email"):
+		mask = maskEmail
+	case strings.Contains(key, "phone"):
+		mask = maskPhone
+	default:
+		return field, false
+	}
+
+	if field.Type != zapcore.StringType {
+		return zap.String(field.Key, redactedValue), true
+	}
+	return zap.String(field.Key, mask(field.String)), true
+}
+
+// isIdentifierKey reports whether key is "id" or ends with "Id" or "_id".
+func isIdentifierKey(key string) bool {
+	return key == "id" || strings.HasSuffix(key, "Id") || strings.HasSuffix(key, "_id")
+}
+
+// maskEmail keeps the first letter and the domain: "john@example.com" → "j***@example.com".
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return redactedValue
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// maskPhone keeps the last two digits: "+375291234567" → "***67".
+func maskPhone(phone string) string {
+	if len(phone) <= 2 {
+		return redactedValue
+	}
+	return "***" + phone[len(phone)-2:]
+}
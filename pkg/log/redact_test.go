@@ -0,0 +1,90 @@
+package log
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+)
+
+func TestRedactField(t *testing.T) {
+	tests := []struct {
+		name   string
+		field  zapcore.Field
+		want   zapcore.Field
+		masked bool
+	}{
+		{"password", zap.String("password", "hunter2"), zap.String("password", redactedValue), true},
+		{"password hash", zap.String("passwordHash", "$2a$10$x"), zap.String("passwordHash", redactedValue), true},
+		{"refresh token", zap.String("refresh_token", "abc"), zap.String("refresh_token", redactedValue), true},
+		{"authorization header", zap.String("Authorization", "Bearer abc"), zap.String("Authorization", redactedValue), true},
+		{"client secret", zap.String("clientSecret", "s"), zap.String("clientSecret", redactedValue), true},
+		{"non-string secret", zap.Int("token", 42), zap.String("token", redactedValue), true},
+		{"email", zap.String("email", "john@example.com"), zap.String("email", "j***@example.com"), true},
+		{"user email", zap.String("userEmail", "a@b.c"), zap.String("userEmail", "a***@b.c"), true},
+		{"non-string email", zap.Int("email", 1), zap.String("email", redactedValue), true},
+		{"phone", zap.String("phone", "+375291234567"), zap.String("phone", "***67"), true},
+		{"token id is kept", zap.String("tokenId", "uuid"), zap.String("tokenId", "uuid"), false},
+		{"snake case token id is kept", zap.String("token_id", "uuid"), zap.String("token_id", "uuid"), false},
+		{"other field is kept", zap.Int("userId", 7), zap.Int("userId", 7), false},
+		{"request id is kept", zap.String("request_id", "r-1"), zap.String("request_id", "r-1"), false},
+		{"plain id is kept", zap.Int("id", 3), zap.Int("id", 3), false},
+		{"secret ending in id is masked", zap.String("tokenpaid", "abc"), zap.String("tokenpaid", redactedValue), true},
+		{"guid of a secret is masked", zap.String("secret_guid", "abc"), zap.String("secret_guid", redactedValue), true},
+		{"upper case ID suffix is not exempt", zap.String("TOKENID", "abc"), zap.String("TOKENID", redactedValue), true},
+		{"email ending in id is masked", zap.String("emailPaid", "john@example.com"), zap.String("emailPaid", "j***@example.com"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, masked := redactField(tt.field)
+			assert.Equal(t, tt.masked, masked)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMaskEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"john@example.com", "j***@example.com"},
+		{"a@b.c", "a***@b.c"},
+		{"first.last+tag@mail.example.org", "f***@mail.example.org"},
+		{"weird@name@example.com", "w***@example.com"},
+		{"@example.com", redactedValue},
+		{"no-at-sign", redactedValue},
+		{"", redactedValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			assert.Equal(t, tt.want, maskEmail(tt.email))
+		})
+	}
+}
+
+func TestMaskPhone(t *testing.T) {
+	assert.Equal(t, "***67", maskPhone("+375291234567"))
+	assert.Equal(t, "***12", maskPhone("012"))
+	assert.Equal(t, redactedValue, maskPhone("12"))
+	assert.Equal(t, redactedValue, maskPhone(""))
+}
+
+func TestRedactCore(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(newRedactCore(core)).With(zap.String("email", "john@example.com"))
+
+	logger.Info("login", zap.String("password", "hunter2"), zap.Int("userId", 7))
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, map[string]interface{}{
+			"email":    "j***@example.com",
+			"password": redactedValue,
+			"userId":   int64(7),
+		}, entries[0].ContextMap())
+	}
+}
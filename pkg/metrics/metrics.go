@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// namespace prefixes the names of all application metrics.
+const namespace = "shop"
+
+// HTTP
+var (
+	// HTTPRequestDuration is the request latency; route is the route template (e.g. /api/cards/:id),
+	// not the raw path, to keep the number of series bounded.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// Database
+var (
+	// DBConnectionLost counts transitions of the Postgres health check from healthy to failing.
+	DBConnectionLost = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_connection_lost_total",
+		Help:      "Number of times the Postgres health check started failing.",
+	})
+
+	// DBReconnects counts transitions of the Postgres health check from failing back to healthy.
+	DBReconnects = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_reconnects_total",
+		Help:      "Number of times the Postgres connection was restored after a failure.",
+	})
+)
+
+// Business events
+var (
+	// OrdersCreated counts committed orders.
+	OrdersCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_created_total",
+		Help:      "Number of created orders.",
+	})
+
+	// SearchZeroResults counts searches that found nothing; source is "cards" (full-text search)
+	// or "suggest" (search suggestions).
+	SearchZeroResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "search_zero_results_total",
+		Help:      "Number of searches that returned no results.",
+	}, []string{"source"})
+)
+
+// RegisterDB exposes db.Stats() of the connection pool: open, in-use and idle connections,
+// wait count and total wait duration, closed connections.
+func RegisterDB(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
@@ -0,0 +1,80 @@
+package migrate
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewParsesFileNames(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_users.up.sql":   {Data: []byte("CREATE TABLE users ();")},
+		"0002_add_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"0001_init.up.sql":        {Data: []byte("CREATE SCHEMA shop;")},
+		"0010_no_down.up.sql":     {Data: []byte("SELECT 1;")},
+		"README.md":               {Data: []byte("ignored")},
+		"0003_Upper.up.sql":       {Data: []byte("ignored: upper case name")},
+		"0004_name.sql":           {Data: []byte("ignored: no direction")},
+		"init.up.sql":             {Data: []byte("ignored: no version")},
+		"0005_dir.up.sql/x.sql":   {Data: []byte("ignored: directory")},
+	}
+
+	migrator, err := New(nil, fsys)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Migration{
+		{Version: 1, Name: "init", Up: "CREATE SCHEMA shop;"},
+		{Version: 2, Name: "add_users", Up: "CREATE TABLE users ();", Down: "DROP TABLE users;"},
+		{Version: 10, Name: "no_down", Up: "SELECT 1;"},
+	}, migrator.migrations)
+}
+
+func TestNewRejectsInvalidSets(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{
+			name: "down without up",
+			fsys: fstest.MapFS{
+				"0001_init.up.sql":   {Data: []byte("SELECT 1;")},
+				"0002_only.down.sql": {Data: []byte("SELECT 1;")},
+			},
+		},
+		{
+			name: "one version with two names",
+			fsys: fstest.MapFS{
+				"0001_init.up.sql":  {Data: []byte("SELECT 1;")},
+				"0001_other.up.sql": {Data: []byte("SELECT 1;")},
+			},
+		},
+		{
+			name: "same version with different padding",
+			fsys: fstest.MapFS{
+				"1_init.up.sql":     {Data: []byte("SELECT 1;")},
+				"0001_init2.up.sql": {Data: []byte("SELECT 1;")},
+			},
+		},
+		{
+			name: "version overflow",
+			fsys: fstest.MapFS{
+				"99999999999999999999_big.up.sql": {Data: []byte("SELECT 1;")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrator, err := New(nil, tt.fsys)
+			assert.Error(t, err)
+			assert.Nil(t, migrator)
+		})
+	}
+}
+
+func TestNewEmpty(t *testing.T) {
+	migrator, err := New(nil, fstest.MapFS{})
+	require.NoError(t, err)
+	assert.Empty(t, migrator.migrations)
+}
@@ -2,6 +2,9 @@ package utils
 
 import (
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"go.uber.org/zap"
 	"shop/pkg/log"
 )
@@ -24,3 +27,26 @@ func DecodeRows[T any](rows *sql.Rows, scanFunc func(*sql.Rows) (T, error)) ([]T
 
 	return items, nil
 }
+
+// EncodeCursor сериализует позицию курсора в непрозрачную строку (base64url от JSON).
+func EncodeCursor[T any](position T) (string, error) {
+	raw, err := json.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// DecodeCursor восстанавливает позицию курсора из строки, полученной от EncodeCursor.
+func DecodeCursor[T any](cursor string) (*T, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	var position T
+	if err := json.Unmarshal(raw, &position); err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	return &position, nil
+}
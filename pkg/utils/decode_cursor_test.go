@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type testCursor struct {
+	Sort string `json:"sort"`
+	Key  string `json:"key"`
+	ID   int    `json:"id"`
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		position testCursor
+	}{
+		{"empty", testCursor{}},
+		{"timestamp key", testCursor{Sort: "newest", Key: "2024-05-01T10:00:00.123456Z", ID: 42}},
+		{"unicode key", testCursor{Sort: "title", Key: "Платье «Лето» & co", ID: 7}},
+		{"url-unsafe key", testCursor{Sort: "title", Key: "a+b/c?d=e", ID: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := EncodeCursor(tt.position)
+			require.NoError(t, err)
+			assert.NotContains(t, cursor, "=")
+			assert.NotContains(t, cursor, "+")
+			assert.NotContains(t, cursor, "/")
+
+			decoded, err := DecodeCursor[testCursor](cursor)
+			require.NoError(t, err)
+			assert.Equal(t, tt.position, *decoded)
+		})
+	}
+}
+
+func TestDecodeCursorRejectsTampered(t *testing.T) {
+	valid, err := EncodeCursor(testCursor{Sort: "newest", Key: "k", ID: 1})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "%%%"},
+		{"padded std base64", base64.StdEncoding.EncodeToString([]byte(`{"id":1}`)) + "=="},
+		{"truncated", valid[:len(valid)-3]},
+		{"not json", base64.RawURLEncoding.EncodeToString([]byte("sort=newest"))},
+		{"wrong field type", base64.RawURLEncoding.EncodeToString([]byte(`{"sort":"newest","id":"1"}`))},
+		{"json array", base64.RawURLEncoding.EncodeToString([]byte(`[1,2]`))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeCursor[testCursor](tt.cursor)
+			assert.Error(t, err)
+			assert.Nil(t, decoded)
+		})
+	}
+}
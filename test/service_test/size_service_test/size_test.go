@@ -40,10 +40,10 @@ func setup() (context.Context, service.SizeServiceInterface) {
 }
 
 func TestGetAllSize(t *testing.T) {
-	_, serv := setup()
+	ctx, serv := setup()
 	// Запуск теста
 	t.Run("Success", func(t *testing.T) {
-		sizeWithPagination, err := serv.GetAllSizes(1, 10)
+		sizeWithPagination, err := serv.GetAllSizes(ctx, 1, 10)
 		// Вывод структуры с ключами
 		fmt.Printf("sizeWithPagination: %+v\n", sizeWithPagination)
 